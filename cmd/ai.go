@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// aiQuestion 取出ai命令后面的问题，整体被一对引号包围时去掉引号。
+// 问题按原文发送，不做通配符等展开
+func aiQuestion(input string) string {
+	question := strings.TrimSpace(strings.TrimPrefix(input, "ai"))
+	if len(question) >= 2 {
+		q := question[0]
+		if (q == '"' || q == '\'') && question[len(question)-1] == q && !strings.ContainsRune(question[1:len(question)-1], rune(q)) {
+			question = question[1 : len(question)-1]
+		}
+	}
+	return question
+}
+
+// HandleAI 处理管道中的 ai [问题]，管道输入作为问题的上下文
+func HandleAI(input string, stdin io.Reader, processQuery func(string, bool)) {
+	question := aiQuestion(input)
+	var content string
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Printf(T("读取管道输入失败: %v\n"), err)
+			return
+		}
+		content = strings.TrimRight(string(data), "\n")
+	}
+
+	switch {
+	case question == "" && content == "":
+		fmt.Println(T(`用法: ai "问题"，或 命令 | ai "问题"`))
+		return
+	case content == "":
+		processQuery(question, false)
+	case question == "":
+		processQuery(content, false)
+	default:
+		fence := codeFence(content)
+		processQuery(fmt.Sprintf("%s\n\n%s\n%s\n%s", question, fence, stripANSI(content), fence), false)
+	}
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:  "ai",
+		Usage: "[问题]",
+		Help:  "向AI提问，在管道中使用时上一个命令的输出作为上下文",
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			input, err := r.expandLastRefs(input)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			HandleAI(input, stdin, r.Query(stdout))
+		},
+	})
+}
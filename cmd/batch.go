@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+)
+
+// BatchItem 批处理输入文件中的一行，prompt和template二选一
+type BatchItem struct {
+	ID       string            `json:"id,omitempty"`
+	Prompt   string            `json:"prompt,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Vars     map[string]string `json:"vars,omitempty"`
+	Model    string            `json:"model,omitempty"`
+}
+
+// BatchResult 批处理输出文件中的一行，Index对应输入文件中的行号(从0开始)
+type BatchResult struct {
+	Index    int         `json:"index"`
+	ID       string      `json:"id,omitempty"`
+	Model    string      `json:"model,omitempty"`
+	Response string      `json:"response,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Attempts int         `json:"attempts"`
+	Usage    *TokenUsage `json:"usage,omitempty"`
+	// FinishReason 为length或content_filter时表示回复不完整
+	FinishReason string `json:"finish_reason,omitempty"`
+}
+
+// BatchOptions 批处理参数
+type BatchOptions struct {
+	Output  string
+	Workers int
+	RPM     int
+	Retries int
+	Resume  bool
+}
+
+// readBatchItems 读取JSONL输入，空行跳过但保留行号
+func readBatchItems(path string) ([]*BatchItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items []*BatchItem
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			items = append(items, nil)
+			continue
+		}
+		item := &BatchItem{}
+		if err := json.Unmarshal(scanner.Bytes(), item); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		if item.Prompt == "" && item.Template == "" {
+			return nil, fmt.Errorf(T("%s:%d: 需要 prompt 或 template 字段"), path, line)
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// readBatchResults 读取已有的输出文件，同一行号以最后一次结果为准
+func readBatchResults(path string) (map[int]*BatchResult, error) {
+	results := map[int]*BatchResult{}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return results, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		r := &BatchResult{}
+		if json.Unmarshal(scanner.Bytes(), r) == nil {
+			results[r.Index] = r
+		}
+	}
+	return results, scanner.Err()
+}
+
+// writeBatchResults 按输入顺序重写输出文件
+func writeBatchResults(path string, results map[int]*BatchResult) error {
+	indexes := make([]int, 0, len(results))
+	for i := range results {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	for _, i := range indexes {
+		if err := enc.Encode(results[i]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// batchPrompt 生成一项的提示词
+func batchPrompt(item *BatchItem) (string, error) {
+	if item.Template != "" {
+		return renderPrompt(item.Template, item.Vars)
+	}
+	return item.Prompt, nil
+}
+
+// retryableError 判断请求失败后是否值得重试：限流(429)、服务端错误(5xx)和网络错误。
+// 密钥错误、模型不存在等4xx错误重试也不会成功
+func retryableError(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.HTTPStatusCode >= 500
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests || reqErr.HTTPStatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// runBatchItem 执行一项请求，限流、服务端错误和网络错误时按指数退避重试，
+// 服务端返回Retry-After时按它等待
+func runBatchItem(client *aiClient, item *BatchItem, index int, options *BatchOptions, limiter <-chan time.Time) *BatchResult {
+	result := &BatchResult{Index: index, ID: item.ID, Model: item.Model}
+	if result.Model == "" {
+		result.Model = client.Model
+	}
+
+	prompt, err := batchPrompt(item)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	backoff := time.Second
+	for attempt := 1; attempt <= options.Retries+1; attempt++ {
+		result.Attempts = attempt
+		if limiter != nil {
+			<-limiter
+		}
+
+		retryAfter := time.Duration(-1)
+		reply, err := client.complete(withRetryAfter(context.Background(), &retryAfter), openai.ChatCompletionRequest{
+			Model:    result.Model,
+			Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: prompt}},
+		})
+		if err == nil {
+			result.Response = reply.Content
+			result.Error = ""
+			result.Usage = newTokenUsage(reply.Usage)
+			result.FinishReason = string(reply.FinishReason)
+			return result
+		}
+
+		result.Error = err.Error()
+		if !retryableError(err) {
+			break
+		}
+		if attempt <= options.Retries {
+			if retryAfter >= 0 {
+				time.Sleep(retryAfter)
+			} else {
+				time.Sleep(backoff)
+			}
+			backoff *= 2
+		}
+	}
+	return result
+}
+
+// RunBatch 并发处理输入文件中的所有请求，结果按输入顺序写入输出文件
+func RunBatch(client *aiClient, input string, options *BatchOptions) error {
+	items, err := readBatchItems(input)
+	if err != nil {
+		return err
+	}
+
+	results := map[int]*BatchResult{}
+	if options.Resume {
+		previous, err := readBatchResults(options.Output)
+		if err != nil {
+			return err
+		}
+		for i, r := range previous {
+			if r.Error == "" && i < len(items) {
+				results[i] = r
+			}
+		}
+	}
+
+	var pending []int
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		if _, done := results[i]; !done {
+			pending = append(pending, i)
+		}
+	}
+	fmt.Fprintf(os.Stderr, T("共 %d 项，已完成 %d 项，待处理 %d 项\n"), len(pending)+len(results), len(results), len(pending))
+
+	// 已完成的结果先写入，中途中断后可以用 --resume 继续
+	if err := writeBatchResults(options.Output, results); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(options.Output, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	var limiter <-chan time.Time
+	if options.RPM > 0 {
+		ticker := time.NewTicker(time.Minute / time.Duration(options.RPM))
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed, finished := 0, 0
+	for w := 0; w < options.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := runBatchItem(client, items[i], i, options, limiter)
+
+				mu.Lock()
+				results[i] = r
+				enc.Encode(r)
+				finished++
+				status := "ok"
+				if r.Error != "" {
+					failed++
+					status = T("失败: ") + r.Error
+				}
+				fmt.Fprintf(os.Stderr, "[%d/%d] #%d %s\n", finished, len(pending), i, status)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := writeBatchResults(options.Output, results); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf(T("%d 项处理失败，可使用 --resume 重试"), failed)
+	}
+	return nil
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch input.jsonl",
+	Short: "批量处理JSONL中的提示词",
+	Long: `批量处理JSONL文件，每行一个请求:
+  {"id": "t-1", "prompt": "..."}
+  {"id": "t-2", "template": "classify", "vars": {"input": "..."}}
+结果按输入顺序写入输出文件，失败的请求会自动重试`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := &BatchOptions{}
+		options.Output, _ = cmd.Flags().GetString("output")
+		options.Workers, _ = cmd.Flags().GetInt("workers")
+		options.RPM, _ = cmd.Flags().GetInt("rpm")
+		options.Retries, _ = cmd.Flags().GetInt("retries")
+		options.Resume, _ = cmd.Flags().GetBool("resume")
+		if options.Workers < 1 {
+			return errors.New(T("--workers 必须大于0"))
+		}
+		if options.Retries < 0 {
+			return errors.New(T("--retries 不能小于0"))
+		}
+
+		client, err := loadAIClient()
+		if err != nil {
+			return err
+		}
+		return RunBatch(client, args[0], options)
+	},
+}
+
+func init() {
+	batchCmd.Flags().StringP("output", "o", "output.jsonl", "输出文件")
+	batchCmd.Flags().IntP("workers", "w", 4, "并发数")
+	batchCmd.Flags().Int("rpm", 0, "每分钟最多请求数，0表示不限制")
+	batchCmd.Flags().Int("retries", 3, "失败重试次数")
+	batchCmd.Flags().Bool("resume", false, "跳过输出文件中已成功的项")
+	rootCmd.AddCommand(batchCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// batchServer 按顺序返回statuses中的状态码，之后总是返回成功的回复
+func batchServer(t *testing.T, header http.Header, statuses ...int) (*aiClient, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			w.Write([]byte(`{"error":{"message":"failed","type":"test"}}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return newAIClient("key", "m1", server.URL, false), &calls
+}
+
+func TestRunBatchItemRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		ok       bool
+	}{
+		{"success", nil, 1, true},
+		{"unauthorized", []int{http.StatusUnauthorized}, 1, false},
+		{"not found", []int{http.StatusNotFound}, 1, false},
+		{"unprocessable", []int{http.StatusUnprocessableEntity}, 1, false},
+		{"server error", []int{http.StatusServiceUnavailable}, 2, true},
+		{"rate limited", []int{http.StatusTooManyRequests}, 2, true},
+		{"retries exhausted", []int{http.StatusInternalServerError, http.StatusInternalServerError}, 2, false},
+	}
+	for _, tt := range tests {
+		// Retry-After: 0 让重试不用等待
+		client, calls := batchServer(t, http.Header{"Retry-After": {"0"}}, tt.statuses...)
+		result := runBatchItem(client, &BatchItem{Prompt: "hi"}, 0, &BatchOptions{Retries: 1}, nil)
+		if result.Attempts != tt.attempts || int(calls.Load()) != tt.attempts {
+			t.Errorf("%s: attempts = %d, requests = %d, want %d", tt.name, result.Attempts, calls.Load(), tt.attempts)
+		}
+		if ok := result.Error == "" && result.Response == "ok"; ok != tt.ok {
+			t.Errorf("%s: result = %+v, want success %v", tt.name, result, tt.ok)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	client, _ := batchServer(t, http.Header{"Retry-After": {"7"}}, http.StatusTooManyRequests)
+	req := openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}}}
+	var retryAfter time.Duration
+	if _, err := client.complete(withRetryAfter(context.Background(), &retryAfter), req); err == nil {
+		t.Fatal("expected a 429 error")
+	}
+	if retryAfter != 7*time.Second {
+		t.Errorf("retryAfter = %v, want 7s", retryAfter)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", -1},
+		{"3", 3 * time.Second},
+		{"0", 0},
+		{"-1", -1},
+		{"soon", -1},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
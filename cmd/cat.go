@@ -2,43 +2,52 @@ package cmd
 
 import (
 	"bufio"
-	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 )
 
+// CatOptions cat命令的选项，flag标签用于/help和Tab补全
 type CatOptions struct {
-	ShowAll         bool
-	NumberNonblank  bool
-	ShowEnds        bool
-	Number          bool
-	SqueezeBlank    bool
-	ShowTabs        bool
-	ShowNonprinting bool
-	Help            bool
-	Version         bool
+	ShowAll         bool   `flag:"-A,--show-all" help:"等同于 -vET"`
+	NumberNonblank  bool   `flag:"-b,--number-nonblank" help:"给非空行编号"`
+	ShowEnds        bool   `flag:"-E,--show-ends" help:"在行尾显示$"`
+	Number          bool   `flag:"-n,--number" help:"给所有行编号"`
+	SqueezeBlank    bool   `flag:"-s,--squeeze-blank" help:"合并连续的空行"`
+	ShowTabs        bool   `flag:"-T,--show-tabs" help:"把TAB显示为^I"`
+	ShowNonprinting bool   `flag:"-v,--show-nonprinting" help:"用^和M-表示不可打印字符"`
+	AISummarize     bool   `flag:"--ai" help:"让AI总结文件内容"`
+	Explain         bool   `flag:"--explain" help:"让AI解释代码"`
+	Ask             string `flag:"--ask" arg:"QUESTION" help:"对文件内容向AI提问"`
+	Help            bool   `flag:"--help" help:"显示帮助"`
+	Version         bool   `flag:"--version" help:"显示版本"`
 }
 
+// parseCatArgs 解析cat的选项和文件，选项可以写在文件之后，如 cat main.go:40-90 --explain
 func parseCatArgs(args []string) (*CatOptions, []string, error) {
 	options := &CatOptions{}
-	flagSet := flag.NewFlagSet("cat", flag.ContinueOnError)
-
-	flagSet.BoolVar(&options.ShowAll, "A", false, "equivalent to -vET")
-	flagSet.BoolVar(&options.ShowAll, "show-all", false, "equivalent to -vET")
-	flagSet.BoolVar(&options.NumberNonblank, "b", false, "number nonempty output lines")
-	flagSet.BoolVar(&options.NumberNonblank, "number-nonblank", false, "number nonempty output lines")
-	flagSet.BoolVar(&options.ShowEnds, "E", false, "display $ at end of each line")
-	flagSet.BoolVar(&options.ShowEnds, "show-ends", false, "display $ at end of each line")
-	flagSet.BoolVar(&options.Number, "n", false, "number all output lines")
-	flagSet.BoolVar(&options.Number, "number", false, "number all output lines")
-	flagSet.BoolVar(&options.SqueezeBlank, "s", false, "suppress repeated empty output lines")
-	flagSet.BoolVar(&options.SqueezeBlank, "squeeze-blank", false, "suppress repeated empty output lines")
-	flagSet.BoolVar(&options.ShowTabs, "T", false, "display TAB characters as ^I")
-	flagSet.BoolVar(&options.ShowTabs, "show-tabs", false, "display TAB characters as ^I")
-	flagSet.BoolVar(&options.ShowNonprinting, "v", false, "use ^ and M- notation")
-	flagSet.BoolVar(&options.ShowNonprinting, "show-nonprinting", false, "use ^ and M- notation")
+	flagSet := pflag.NewFlagSet("cat", pflag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	flagSet.SetInterspersed(true)
+
+	flagSet.BoolVarP(&options.ShowAll, "show-all", "A", false, "equivalent to -vET")
+	flagSet.BoolVarP(&options.NumberNonblank, "number-nonblank", "b", false, "number nonempty output lines")
+	flagSet.BoolVarP(&options.ShowEnds, "show-ends", "E", false, "display $ at end of each line")
+	flagSet.BoolVarP(&options.Number, "number", "n", false, "number all output lines")
+	flagSet.BoolVarP(&options.SqueezeBlank, "squeeze-blank", "s", false, "suppress repeated empty output lines")
+	flagSet.BoolVarP(&options.ShowTabs, "show-tabs", "T", false, "display TAB characters as ^I")
+	flagSet.BoolVarP(&options.ShowNonprinting, "show-nonprinting", "v", false, "use ^ and M- notation")
+	flagSet.BoolVar(&options.AISummarize, "ai", false, "summarize the contents with AI")
+	flagSet.BoolVar(&options.Explain, "explain", false, "explain the code with AI")
+	flagSet.StringVar(&options.Ask, "ask", "", "ask AI a question about the contents")
 	flagSet.BoolVar(&options.Help, "help", false, "display help")
 	flagSet.BoolVar(&options.Version, "version", false, "display version")
 
@@ -50,60 +59,154 @@ func parseCatArgs(args []string) (*CatOptions, []string, error) {
 	return options, flagSet.Args(), nil
 }
 
-func HandleCat(prompt string) {
-	args := strings.Fields(prompt)[1:] // Skip "cat"
+// HandleCat 处理cat命令，输出文件内容，--ai、--explain、--ask 时再把内容发给AI
+func HandleCat(prompt string, stdin io.Reader, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "cat"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
 	options, files, err := parseCatArgs(args)
 	if err != nil {
-		fmt.Printf("Error parsing arguments: %v\n", err)
+		fmt.Printf(T("参数解析失败: %v\n"), err)
 		return
 	}
+	if err := runCat(files, options, stdin, w, processQuery); err != nil && err != errReported {
+		fmt.Println(err)
+	}
+}
+
+// catRange 文件名后 :起始行-结束行 选择的行，为零值时表示整个文件，end为0表示到文件末尾
+type catRange struct {
+	start, end int
+}
+
+func (r catRange) String() string {
+	switch {
+	case r.start == 0:
+		return ""
+	case r.end == 0:
+		return fmt.Sprintf("%d-", r.start)
+	case r.end == r.start:
+		return strconv.Itoa(r.start)
+	default:
+		return fmt.Sprintf("%d-%d", r.start, r.end)
+	}
+}
+
+var catRangePattern = regexp.MustCompile(`^(.+):(\d+)(-(\d*))?$`)
+
+// parseCatRange 拆分 file.go:40-90、file.go:40- 和 file.go:40 形式的行范围。
+// 存在同名文件时不拆分
+func parseCatRange(arg string) (string, catRange, error) {
+	m := catRangePattern.FindStringSubmatch(arg)
+	if m == nil {
+		return arg, catRange{}, nil
+	}
+	if _, err := os.Stat(arg); err == nil {
+		return arg, catRange{}, nil
+	}
+	r := catRange{}
+	r.start, _ = strconv.Atoi(m[2])
+	r.end = r.start
+	if m[3] != "" {
+		r.end, _ = strconv.Atoi(m[4])
+	}
+	if r.start < 1 || (r.end != 0 && r.end < r.start) {
+		return "", catRange{}, fmt.Errorf(T("无效的行范围: %s"), arg)
+	}
+	return m[1], r, nil
+}
 
+// runCat 依次输出各个文件，无法读取的文件输出错误后继续，有失败时返回errReported。
+// 文件名可以带行范围，如 main.go:40-90。--ai、--explain、--ask 时把每个文件的内容发给AI
+func runCat(files []string, options *CatOptions, stdin io.Reader, w io.Writer, processQuery func(string, bool)) error {
 	if options.Help {
-		printCatHelp()
-		return
+		printCatHelp(w)
+		return nil
 	}
 
 	if options.Version {
-		fmt.Println("cat (ai-cli) 1.0")
-		return
+		fmt.Fprintln(w, "cat (ai-cli) 1.0")
+		return nil
 	}
 
+	// 不在管道中时读取终端输入
+	if stdin == nil {
+		stdin = os.Stdin
+	}
 	if len(files) == 0 {
-		// Read from stdin
-		catFile(os.Stdin, "", options)
-		return
+		files = []string{"-"}
 	}
 
+	keep := options.AISummarize || options.Explain || options.Ask != ""
+	failed := false
 	for _, file := range files {
-		if file == "-" {
-			catFile(os.Stdin, "", options)
+		file, lines, err := parseCatRange(file)
+		if err != nil {
+			fmt.Printf("cat: %v\n", err)
+			failed = true
 			continue
 		}
 
-		f, err := os.Open(file)
-		if err != nil {
-			fmt.Printf("cat: %s: %v\n", file, err)
-			continue
+		var content string
+		if file == "-" {
+			// Read from stdin
+			content = catFile(stdin, "", lines, options, w, keep)
+		} else {
+			f, err := os.Open(file)
+			if err != nil {
+				fmt.Printf("cat: %s: %v\n", file, err)
+				failed = true
+				continue
+			}
+			content = catFile(f, file, lines, options, w, keep)
+			f.Close()
 		}
-		defer f.Close()
 
-		catFile(f, file, options)
+		if keep && strings.TrimSpace(content) != "" {
+			if err := askCat(file, lines, content, options, w, processQuery); err != nil {
+				return err
+			}
+		}
+	}
+	if failed {
+		return errReported
 	}
+	return nil
 }
 
-func catFile(f *os.File, filename string, options *CatOptions) {
+// catFile 输出f的内容，lines不为空时只输出选择的行，行号从选择的第一行开始。
+// keep为true时返回输出的原始内容，供AI使用
+func catFile(f io.Reader, filename string, lines catRange, options *CatOptions, w io.Writer, keep bool) string {
 	reader := bufio.NewReader(f)
-	lineNum := 1
+	lineNum := max(lines.start, 1)
 	lastLineEmpty := false
+	var raw strings.Builder
 
-	for {
+	for fileLine := 1; ; fileLine++ {
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			if err != io.EOF {
 				fmt.Printf("cat: %s: %v\n", filename, err)
 			}
+			// 最后一行没有换行符时也要输出
+			if line == "" {
+				break
+			}
+		}
+		if lines.end > 0 && fileLine > lines.end {
 			break
 		}
+		if fileLine < lines.start {
+			if err != nil {
+				break
+			}
+			continue
+		}
+		if keep {
+			raw.WriteString(line)
+		}
 
 		// Handle -s (squeeze-blank)
 		if options.SqueezeBlank {
@@ -119,7 +222,7 @@ func catFile(f *os.File, filename string, options *CatOptions) {
 
 		// Handle -n (number) and -b (number-nonblank)
 		if options.Number || (options.NumberNonblank && strings.TrimSpace(line) != "") {
-			fmt.Printf("%6d\t", lineNum)
+			fmt.Fprintf(w, "%6d\t", lineNum)
 			lineNum++
 		}
 
@@ -135,8 +238,113 @@ func catFile(f *os.File, filename string, options *CatOptions) {
 			processed = strings.ReplaceAll(processed, "\t", "^I")
 		}
 
-		fmt.Print(processed)
+		fmt.Fprint(w, processed)
+		if err != nil {
+			break
+		}
+	}
+	return raw.String()
+}
+
+// catLanguages 按扩展名或文件名判断的语言，用于提示词
+var catLanguages = map[string]string{
+	".go": "Go", ".py": "Python", ".js": "JavaScript", ".jsx": "JavaScript", ".ts": "TypeScript", ".tsx": "TypeScript",
+	".java": "Java", ".kt": "Kotlin", ".scala": "Scala", ".rs": "Rust", ".c": "C", ".h": "C",
+	".cpp": "C++", ".cc": "C++", ".hpp": "C++", ".cs": "C#", ".rb": "Ruby", ".php": "PHP",
+	".swift": "Swift", ".dart": "Dart", ".lua": "Lua", ".sh": "Shell", ".bash": "Shell", ".zsh": "Shell",
+	".sql": "SQL", ".html": "HTML", ".css": "CSS", ".vue": "Vue", ".json": "JSON", ".yaml": "YAML",
+	".yml": "YAML", ".toml": "TOML", ".xml": "XML", ".md": "Markdown",
+	"Makefile": "Makefile", "Dockerfile": "Dockerfile",
+}
+
+// catLanguage 返回文件的语言和代码块标记，无法判断时都为空
+func catLanguage(file string) (language, fence string) {
+	base := filepath.Base(file)
+	if language, ok := catLanguages[base]; ok {
+		return language, strings.ToLower(base)
+	}
+	ext := strings.ToLower(filepath.Ext(base))
+	if language, ok := catLanguages[ext]; ok {
+		return language, ext[1:]
+	}
+	return "", ""
+}
+
+// defaultChunkSize 没有配置ai.chunkSize时每次发给AI的最大字节数
+const defaultChunkSize = 12000
+
+// catChunk 分段发送时的一段内容及其行范围
+type catChunk struct {
+	lines   catRange
+	content string
+}
+
+// splitCatChunks 按行把内容分成不超过size字节的段，超过size的单行单独成段
+func splitCatChunks(content string, first, size int) []catChunk {
+	var chunks []catChunk
+	var b strings.Builder
+	start, num := first, first
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		if b.Len() > 0 && b.Len()+len(line) > size {
+			chunks = append(chunks, catChunk{catRange{start, num - 1}, b.String()})
+			b.Reset()
+			start = num
+		}
+		b.WriteString(line)
+		num++
+	}
+	if b.Len() > 0 {
+		chunks = append(chunks, catChunk{catRange{start, num - 1}, b.String()})
 	}
+	return chunks
+}
+
+// askCat 按 --ai、--explain、--ask 把文件内容发给AI，超过ai.chunkSize时分段发送
+func askCat(file string, lines catRange, content string, options *CatOptions, w io.Writer, processQuery func(string, bool)) error {
+	mode, title := "summarize", T("AI总结:")
+	switch {
+	case options.Ask != "":
+		mode, title = "ask", T("AI回答:")
+	case options.Explain:
+		mode, title = "explain", T("AI解释:")
+	}
+	if file == "-" {
+		file = "(standard input)"
+	}
+	language, fenceTag := catLanguage(file)
+	size := viper.GetInt("ai.chunkSize")
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	chunks := splitCatChunks(content, max(lines.start, 1), size)
+	fmt.Fprintln(w, title)
+	for i, chunk := range chunks {
+		vars := map[string]string{
+			"file":     file,
+			"language": language,
+			"mode":     mode,
+			"question": options.Ask,
+			"content":  codeFence(chunk.content) + fenceTag + "\n" + strings.TrimRight(chunk.content, "\n") + "\n" + codeFence(chunk.content),
+		}
+		// 只选择了部分行或者分段发送时告诉AI行号
+		if lines.start > 0 || len(chunks) > 1 {
+			vars["lines"] = chunk.lines.String()
+		}
+		if len(chunks) > 1 {
+			vars["part"] = fmt.Sprintf("%d/%d", i+1, len(chunks))
+			fmt.Fprintf(w, T("第 %d/%d 段 (第 %s 行):\n"), i+1, len(chunks), chunk.lines)
+		}
+		prompt, err := renderPrompt("cat-ai", vars)
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		processQuery(prompt, true)
+	}
+	return nil
 }
 
 func showNonprinting(s string) string {
@@ -158,11 +366,12 @@ func showNonprinting(s string) string {
 	return result.String()
 }
 
-func printCatHelp() {
-	fmt.Println(`Usage: cat [OPTION]... [FILE]...
+func printCatHelp(w io.Writer) {
+	fmt.Fprintln(w, `Usage: cat [OPTION]... [FILE[:START-END]]...
 Concatenate FILE(s) to standard output.
 
 With no FILE, or when FILE is -, read standard input.
+FILE:START-END, FILE:START- and FILE:LINE print only the selected lines.
 
   -A, --show-all           equivalent to -vET
   -b, --number-nonblank    number nonempty output lines, overrides -n
@@ -171,10 +380,56 @@ With no FILE, or when FILE is -, read standard input.
   -s, --squeeze-blank      suppress repeated empty output lines
   -T, --show-tabs          display TAB characters as ^I
   -v, --show-nonprinting   use ^ and M- notation, except for LFD and TAB
+      --ai                 summarize the contents with AI
+      --explain            explain the code with AI
+      --ask QUESTION       ask AI a question about the contents
       --help        display this help and exit
       --version     output version information and exit
 
 Examples:
   cat f - g  Output f's contents, then standard input, then g's contents.
-  cat        Copy standard input to standard output.`)
+  cat        Copy standard input to standard output.
+  cat main.go:40-90 --explain  Print lines 40 to 90 of main.go and explain them.`)
+}
+
+func newCatCmd() *cobra.Command {
+	options := &CatOptions{}
+	cmd := &cobra.Command{
+		Use:   "cat [文件]...",
+		Short: "显示文件内容",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCat(args, options, os.Stdin, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newCatCmd())
+	registerCommand(&Command{
+		Name:     "cat",
+		Usage:    "[选项] 文件[:起始行-结束行]...",
+		Help:     "显示文件内容",
+		Flags:    optionFlags(CatOptions{}),
+		Record:   true,
+		Complete: completeFiles,
+		Operands: func(args []string) ([]string, error) {
+			_, files, err := parseCatArgs(args)
+			var paths []string
+			for _, file := range files {
+				// 去掉 :起始行-结束行，- 表示管道输入
+				if path, _, err := parseCatRange(file); err == nil {
+					file = path
+				}
+				if file != "-" {
+					paths = append(paths, file)
+				}
+			}
+			return paths, err
+		},
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleCat(input, stdin, stdout, r.Query(stdout))
+		},
+	})
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCatArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		options CatOptions
+		files   []string
+	}{
+		{[]string{"-n", "a.txt"}, CatOptions{Number: true}, []string{"a.txt"}},
+		{[]string{"main.go:40-90", "--explain"}, CatOptions{Explain: true}, []string{"main.go:40-90"}},
+		{[]string{"a.txt", "--ai", "b.txt"}, CatOptions{AISummarize: true}, []string{"a.txt", "b.txt"}},
+		{[]string{"main.go", "--ask", "where is the config loaded?"}, CatOptions{Ask: "where is the config loaded?"}, []string{"main.go"}},
+		{[]string{"a.txt", "-nE", "-"}, CatOptions{Number: true, ShowEnds: true}, []string{"a.txt", "-"}},
+		{[]string{"--", "--ai"}, CatOptions{}, []string{"--ai"}},
+	}
+	for _, tt := range tests {
+		options, files, err := parseCatArgs(tt.args)
+		if err != nil {
+			t.Errorf("parseCatArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if *options != tt.options || !reflect.DeepEqual(files, tt.files) {
+			t.Errorf("parseCatArgs(%q) = %+v, %q, want %+v, %q", tt.args, *options, files, tt.options, tt.files)
+		}
+	}
+
+	if _, _, err := parseCatArgs([]string{"a.txt", "--unknown"}); err == nil {
+		t.Error("parseCatArgs accepted an unknown option")
+	}
+}
+
+func TestParseCatRange(t *testing.T) {
+	tests := []struct {
+		arg   string
+		file  string
+		lines catRange
+		err   bool
+	}{
+		{"main.go", "main.go", catRange{}, false},
+		{"main.go:40-90", "main.go", catRange{40, 90}, false},
+		{"main.go:40-", "main.go", catRange{40, 0}, false},
+		{"main.go:40", "main.go", catRange{40, 40}, false},
+		{"main.go:90-40", "", catRange{}, true},
+		{"main.go:0", "", catRange{}, true},
+	}
+	for _, tt := range tests {
+		file, lines, err := parseCatRange(tt.arg)
+		if (err != nil) != tt.err || file != tt.file || lines != tt.lines {
+			t.Errorf("parseCatRange(%q) = %q, %v, %v", tt.arg, file, lines, err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shortPath 把用户主目录显示为~
+func shortPath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rest
+	}
+	return path
+}
+
+// chdir 切换工作目录并记录上一个目录，供 cd - 使用
+func (r *REPL) chdir(dir string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(dir); err != nil {
+		return err
+	}
+	r.prevDir = cwd
+	return nil
+}
+
+// HandleCd 处理 cd [目录|-]，不带参数时回到主目录
+func HandleCd(input string, r *REPL, w io.Writer) {
+	args, err := builtinArgs(input) // Skip "cd"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if len(args) > 1 {
+		fmt.Println(T("cd: 参数过多"))
+		return
+	}
+
+	var dir string
+	switch {
+	case len(args) == 0:
+		if dir, err = os.UserHomeDir(); err != nil {
+			fmt.Printf("cd: %v\n", err)
+			return
+		}
+	case args[0] == "-":
+		if r.prevDir == "" {
+			fmt.Println(T("cd: 没有上一个目录"))
+			return
+		}
+		dir = r.prevDir
+	default:
+		dir = args[0]
+	}
+	if err := r.chdir(dir); err != nil {
+		fmt.Printf("cd: %v\n", err)
+		return
+	}
+	if len(args) > 0 && args[0] == "-" {
+		HandlePwd(w)
+	}
+}
+
+// HandlePwd 输出当前工作目录
+func HandlePwd(w io.Writer) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("pwd: %v\n", err)
+		return
+	}
+	fmt.Fprintln(w, cwd)
+}
+
+// HandlePushd 处理 pushd [目录]：把当前目录压入目录栈后切换，不带参数时与栈顶交换
+func HandlePushd(input string, r *REPL, w io.Writer) {
+	args, err := builtinArgs(input) // Skip "pushd"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if len(args) > 1 {
+		fmt.Println(T("pushd: 参数过多"))
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("pushd: %v\n", err)
+		return
+	}
+
+	swap := len(args) == 0
+	if swap && len(r.dirStack) == 0 {
+		fmt.Println(T("pushd: 目录栈为空"))
+		return
+	}
+	dir := ""
+	if swap {
+		dir = r.dirStack[len(r.dirStack)-1]
+	} else {
+		dir = args[0]
+	}
+	if err := r.chdir(dir); err != nil {
+		fmt.Printf("pushd: %v\n", err)
+		return
+	}
+	if swap {
+		r.dirStack = r.dirStack[:len(r.dirStack)-1]
+	}
+	r.dirStack = append(r.dirStack, cwd)
+	r.printDirs(w)
+}
+
+// HandlePopd 处理 popd：弹出目录栈顶并切换过去
+func HandlePopd(r *REPL, w io.Writer) {
+	if len(r.dirStack) == 0 {
+		fmt.Println(T("popd: 目录栈为空"))
+		return
+	}
+	dir := r.dirStack[len(r.dirStack)-1]
+	if err := r.chdir(dir); err != nil {
+		fmt.Printf("popd: %v\n", err)
+		return
+	}
+	r.dirStack = r.dirStack[:len(r.dirStack)-1]
+	r.printDirs(w)
+}
+
+// printDirs 按bash dirs的格式输出当前目录和目录栈，栈顶在前
+func (r *REPL) printDirs(w io.Writer) {
+	cwd, _ := os.Getwd()
+	dirs := []string{shortPath(cwd)}
+	for i := len(r.dirStack) - 1; i >= 0; i-- {
+		dirs = append(dirs, shortPath(r.dirStack[i]))
+	}
+	fmt.Fprintln(w, strings.Join(dirs, " "))
+}
+
+// completeDirs 只补全目录
+func completeDirs(r *REPL, args []string, word string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	var dirs []string
+	for _, path := range completePaths(word) {
+		if strings.HasSuffix(path, "/") {
+			dirs = append(dirs, path)
+		}
+	}
+	return dirs
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:     "cd",
+		Usage:    "[目录|-]",
+		Help:     "切换工作目录，cd - 回到上一个目录",
+		Complete: completeDirs,
+		Operands: func(args []string) ([]string, error) { return args, nil },
+		Run:      func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleCd(input, r, stdout) },
+	})
+	registerCommand(&Command{
+		Name:   "pwd",
+		Help:   "显示当前工作目录",
+		NoArgs: true,
+		Run:    func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandlePwd(stdout) },
+	})
+	registerCommand(&Command{
+		Name:     "pushd",
+		Usage:    "[目录]",
+		Help:     "把当前目录压入目录栈并切换目录",
+		Complete: completeDirs,
+		Run:      func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandlePushd(input, r, stdout) },
+	})
+	registerCommand(&Command{
+		Name:   "popd",
+		Help:   "弹出目录栈顶并切换过去",
+		NoArgs: true,
+		Run:    func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandlePopd(r, stdout) },
+	})
+}
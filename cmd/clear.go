@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
@@ -16,9 +17,18 @@ func HandleClear() {
 	case "linux", "darwin": // darwin是MacOS
 		cmd = exec.Command("clear")
 	default:
-		fmt.Println("不支持的操作系统")
+		fmt.Println(T("不支持的操作系统"))
 		return
 	}
 	cmd.Stdout = os.Stdout
 	cmd.Run()
 }
+
+func init() {
+	registerCommand(&Command{
+		Name:   "clear",
+		Help:   "清空终端屏幕",
+		NoArgs: true,
+		Run:    func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleClear() },
+	})
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/viper"
+)
+
+// aiClient 封装OpenAI兼容接口的客户端和默认模型
+type aiClient struct {
+	*openai.Client
+	Model  string
+	Stream bool
+}
+
+func newAIClient(apiKey, model, basePath string, stream bool) *aiClient {
+	config := openai.DefaultConfig(apiKey)
+	if basePath != "" {
+		config.BaseURL = basePath
+	}
+	config.HTTPClient = &http.Client{Transport: retryAfterTransport{http.DefaultTransport}}
+	return &aiClient{
+		Client: openai.NewClientWithConfig(config),
+		Model:  model,
+		Stream: stream,
+	}
+}
+
+// retryAfterKey 请求context中保存Retry-After的*time.Duration，见withRetryAfter
+type retryAfterKey struct{}
+
+// withRetryAfter 返回的context用于发送请求时，响应中的Retry-After会写入*d，没有时为-1
+func withRetryAfter(ctx context.Context, d *time.Duration) context.Context {
+	return context.WithValue(ctx, retryAfterKey{}, d)
+}
+
+// retryAfterTransport 记录响应的Retry-After头。go-openai返回的错误不包含响应头，
+// 只能在这里取出
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if d, ok := req.Context().Value(retryAfterKey{}).(*time.Duration); ok && err == nil {
+		*d = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return resp, err
+}
+
+// parseRetryAfter 解析秒数或HTTP日期形式的Retry-After，没有或无效时返回-1，日期已过去时返回0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return -1
+}
+
+// loadAIClient 根据配置文件创建客户端，设置了ai.profile时使用该profile的配置
+func loadAIClient() (*aiClient, error) {
+	profile := viper.GetString("ai.profile")
+	if profile != "" && !viper.IsSet("profiles."+profile) {
+		return nil, fmt.Errorf(T("配置中没有profile: %s"), profile)
+	}
+	apiKey, model, basePath := profileConfig(profile)
+	if apiKey == "" {
+		return nil, errors.New(T("请在config.yaml中配置API密钥"))
+	}
+	return newAIClient(apiKey, model, basePath, viper.GetBool("ai.stream")), nil
+}
+
+// profileConfig 返回profiles中name的apiKey、模型和basePath，没有配置的项使用ai下的默认值。
+// name为空时返回ai下的配置
+func profileConfig(name string) (apiKey, model, basePath string) {
+	apiKey = viper.GetString("ai.apiKey")
+	model = viper.GetString("ai.model")
+	basePath = viper.GetString("ai.basePath")
+	if name == "" {
+		return apiKey, model, basePath
+	}
+	key := "profiles." + name
+	if v := viper.GetString(key + ".apiKey"); v != "" {
+		apiKey = v
+	}
+	if v := viper.GetString(key + ".model"); v != "" {
+		model = v
+	}
+	if viper.IsSet(key + ".basePath") {
+		basePath = viper.GetString(key + ".basePath")
+	}
+	return apiKey, model, basePath
+}
+
+// chatReply 一次调用的结果，Reasoning是推理模型单独返回的思考过程
+type chatReply struct {
+	Content      string
+	Reasoning    string
+	FinishReason openai.FinishReason
+	Usage        *openai.Usage
+}
+
+// continuePrompt 回复被截断后请求模型续写的提示
+const continuePrompt = "你的上一条回复因长度限制被截断了，请从中断的地方直接继续输出，不要重复已经输出的内容，也不要添加任何说明。"
+
+// merge 把续写的结果拼接到已有回复上
+func (r *chatReply) merge(next *chatReply) {
+	r.Content += next.Content
+	r.Reasoning += next.Reasoning
+	r.FinishReason = next.FinishReason
+	if next.Usage == nil {
+		return
+	}
+	if r.Usage == nil {
+		r.Usage = &openai.Usage{}
+	}
+	r.Usage.PromptTokens += next.Usage.PromptTokens
+	r.Usage.CompletionTokens += next.Usage.CompletionTokens
+	r.Usage.TotalTokens += next.Usage.TotalTokens
+	if next.Usage.CompletionTokensDetails != nil {
+		if r.Usage.CompletionTokensDetails == nil {
+			r.Usage.CompletionTokensDetails = &openai.CompletionTokensDetails{}
+		}
+		r.Usage.CompletionTokensDetails.ReasoningTokens += next.Usage.CompletionTokensDetails.ReasoningTokens
+	}
+}
+
+// complete 以非流式方式发送消息
+func (c *aiClient) complete(ctx context.Context, req openai.ChatCompletionRequest) (*chatReply, error) {
+	if req.Model == "" {
+		req.Model = c.Model
+	}
+	resp, err := c.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf(T("模型 %s 没有返回任何结果"), req.Model)
+	}
+	return &chatReply{
+		Content:      resp.Choices[0].Message.Content,
+		Reasoning:    resp.Choices[0].Message.ReasoningContent,
+		FinishReason: resp.Choices[0].FinishReason,
+		Usage:        &resp.Usage,
+	}, nil
+}
+
+// stream 以流式方式发送消息，每收到一段回复或思考过程调用onDelta，返回拼接后的完整结果
+func (c *aiClient) stream(ctx context.Context, req openai.ChatCompletionRequest, onDelta func(content, reasoning string)) (*chatReply, error) {
+	if req.Model == "" {
+		req.Model = c.Model
+	}
+	req.Stream = true
+	req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+	stream, err := c.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var content, reasoning strings.Builder
+	reply := &chatReply{}
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			reply.Content, reply.Reasoning = content.String(), reasoning.String()
+			return reply, err
+		}
+		if response.Usage != nil {
+			reply.Usage = response.Usage
+		}
+		// 携带用量的最后一块和部分服务端的保活数据块没有choices
+		if len(response.Choices) == 0 {
+			continue
+		}
+		if response.Choices[0].FinishReason != "" {
+			reply.FinishReason = response.Choices[0].FinishReason
+		}
+		delta := response.Choices[0].Delta
+		content.WriteString(delta.Content)
+		reasoning.WriteString(delta.ReasoningContent)
+		if onDelta != nil {
+			onDelta(delta.Content, delta.ReasoningContent)
+		}
+	}
+	reply.Content, reply.Reasoning = content.String(), reasoning.String()
+	return reply, nil
+}
+
+// chat 按客户端配置以流式或非流式发送消息。回复因长度被截断时，
+// 按 ai.autoContinue 配置的次数请求模型续写，并把各段拼接成一个回复
+func (c *aiClient) chat(ctx context.Context, req openai.ChatCompletionRequest, onDelta func(content, reasoning string)) (*chatReply, error) {
+	maxContinue := viper.GetInt("ai.autoContinue")
+	req.Messages = append([]openai.ChatCompletionMessage(nil), req.Messages...)
+
+	total := &chatReply{}
+	for i := 0; ; i++ {
+		var reply *chatReply
+		var err error
+		if c.Stream {
+			reply, err = c.stream(ctx, req, onDelta)
+		} else {
+			reply, err = c.complete(ctx, req)
+		}
+		if reply != nil {
+			total.merge(reply)
+		}
+		if err != nil {
+			if total.Content == "" && total.Reasoning == "" {
+				return nil, err
+			}
+			return total, err
+		}
+		if reply.FinishReason != openai.FinishReasonLength || i >= maxContinue {
+			return total, nil
+		}
+		req.Messages = append(req.Messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: reply.Content},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: continuePrompt},
+		)
+	}
+}
+
+// resolveTarget 把名称解析为客户端：优先匹配配置中的profiles，否则作为模型名使用默认配置
+func resolveTarget(name string) (*aiClient, error) {
+	base, err := loadAIClient()
+	if err != nil {
+		return nil, err
+	}
+	if !viper.IsSet("profiles." + name) {
+		base.Model = name
+		return base, nil
+	}
+	apiKey, model, basePath := profileConfig(name)
+	return newAIClient(apiKey, model, basePath, base.Stream), nil
+}
@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+)
+
+// CompareResult 一个模型的回复及统计
+type CompareResult struct {
+	Target    string
+	Model     string
+	Reply     string
+	Usage     *openai.Usage
+	FirstByte time.Duration
+	Latency   time.Duration
+	Err       error
+}
+
+func (r *CompareResult) stats() string {
+	if r.Err != nil {
+		return fmt.Sprintf(T("失败: %v"), r.Err)
+	}
+	s := fmt.Sprintf(T("首字 %.2fs · 总耗时 %.2fs"), r.FirstByte.Seconds(), r.Latency.Seconds())
+	if r.Usage != nil {
+		s += fmt.Sprintf(" · tokens %d/%d/%d", r.Usage.PromptTokens, r.Usage.CompletionTokens, r.Usage.TotalTokens)
+	}
+	return s
+}
+
+// compareView 按顺序逐段输出各模型的回复：当前段实时输出，后面的段先缓冲，轮到时再输出
+type compareView struct {
+	mu       sync.Mutex
+	w        io.Writer
+	results  []*CompareResult
+	buffers  []strings.Builder
+	done     []bool
+	current  int
+	sections bool
+}
+
+func newCompareView(w io.Writer, results []*CompareResult, sections bool) *compareView {
+	v := &compareView{
+		w:        w,
+		results:  results,
+		buffers:  make([]strings.Builder, len(results)),
+		done:     make([]bool, len(results)),
+		sections: sections,
+	}
+	if sections {
+		v.header(0)
+	}
+	return v
+}
+
+func (v *compareView) header(i int) {
+	fmt.Fprintf(v.w, "\n=== [%d] %s ===\n", i+1, v.results[i].Target)
+}
+
+func (v *compareView) write(i int, delta string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.buffers[i].WriteString(delta)
+	if v.sections && i == v.current {
+		fmt.Fprint(v.w, delta)
+	}
+}
+
+func (v *compareView) finish(i int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.done[i] = true
+	if !v.sections {
+		return
+	}
+	for v.current < len(v.results) && v.done[v.current] {
+		fmt.Fprintf(v.w, "\n--- %s ---\n", v.results[v.current].stats())
+		v.current++
+		if v.current < len(v.results) {
+			v.header(v.current)
+			fmt.Fprint(v.w, v.buffers[v.current].String())
+		}
+	}
+}
+
+// printColumns 把所有回复并排输出
+func printColumns(w io.Writer, results []*CompareResult) {
+	width := terminalWidth()
+	if width <= 0 {
+		width = 160
+	}
+	colWidth := (width - 3*(len(results)-1)) / len(results)
+	if colWidth < 20 {
+		colWidth = 20
+	}
+
+	columns := make([][]string, len(results))
+	rows := 0
+	for i, r := range results {
+		text := r.Reply
+		if r.Err != nil && text == "" {
+			text = r.Err.Error()
+		}
+		columns[i] = append([]string{fmt.Sprintf("[%d] %s", i+1, r.Target), strings.Repeat("-", colWidth)}, wrapText(text, colWidth)...)
+		columns[i] = append(columns[i], strings.Repeat("-", colWidth))
+		columns[i] = append(columns[i], wrapText(r.stats(), colWidth)...)
+		if len(columns[i]) > rows {
+			rows = len(columns[i])
+		}
+	}
+
+	for row := 0; row < rows; row++ {
+		var cells []string
+		for _, col := range columns {
+			cell := ""
+			if row < len(col) {
+				cell = col[row]
+			}
+			cells = append(cells, padRight(cell, colWidth))
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, " │ "), " "))
+	}
+}
+
+// RunCompare 把同一段对话并行发送给多个模型或profile
+func RunCompare(targets []string, messages []openai.ChatCompletionMessage, columns bool, w io.Writer) []*CompareResult {
+	results := make([]*CompareResult, len(targets))
+	for i, target := range targets {
+		results[i] = &CompareResult{Target: target}
+	}
+	view := newCompareView(w, results, !columns)
+
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := results[i]
+			client, err := resolveTarget(r.Target)
+			if err != nil {
+				r.Err = err
+				view.finish(i)
+				return
+			}
+			r.Model = client.Model
+
+			start := time.Now()
+			reply, err := client.stream(context.Background(), openai.ChatCompletionRequest{
+				Messages: messages,
+			}, func(content, _ string) {
+				if r.FirstByte == 0 {
+					r.FirstByte = time.Since(start)
+				}
+				view.write(i, content)
+			})
+			r.Latency = time.Since(start)
+			r.Err = err
+			if reply != nil {
+				r.Reply, r.Usage = reply.Content, reply.Usage
+			}
+			view.finish(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if columns {
+		printColumns(w, results)
+	}
+	return results
+}
+
+// pickCompareResult 询问用户保留哪个回复，返回nil表示都不保留
+func pickCompareResult(results []*CompareResult, readLine func(prompt string) (string, bool)) *CompareResult {
+	line, ok := readLine(fmt.Sprintf(T("保留哪个回复到会话中? [1-%d，回车跳过]: "), len(results)))
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || n < 1 || n > len(results) || results[n-1].Err != nil {
+		return nil
+	}
+	return results[n-1]
+}
+
+// keepCompareResult 把选中的回复作为一轮正常对话记录到会话中
+func keepCompareResult(session *Session, prompt string, r *CompareResult) {
+	session.AddUser(prompt)
+	session.AddAssistant(r.Model, r.Reply, "", newTokenUsage(r.Usage))
+	fmt.Printf(T("已保留 [%s] 的回复\n"), r.Target)
+}
+
+// HandleCompare 处理REPL中的 /compare modelA modelB ... [-- 问题]，没有给出问题时再读取一行
+func HandleCompare(input string, session *Session, readLine func(prompt string) (string, bool), w io.Writer) {
+	// "--"之后是问题原文，不按参数拆分
+	head, prompt, _ := strings.Cut(input+" ", " -- ")
+	prompt = strings.TrimSpace(prompt)
+	args, err := builtinArgs(head) // Skip "/compare"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	columns := false
+	var targets []string
+	for _, arg := range args {
+		if arg == "--columns" {
+			columns = true
+			continue
+		}
+		targets = append(targets, arg)
+	}
+	if len(targets) < 2 {
+		fmt.Println(T("用法: /compare [--columns] modelA modelB ... [-- 问题]"))
+		return
+	}
+
+	if prompt == "" {
+		line, ok := readLine(T("请输入要对比的问题: "))
+		if !ok || strings.TrimSpace(line) == "" {
+			return
+		}
+		prompt = line
+	}
+
+	messages := append(session.Messages(), openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: prompt})
+	results := RunCompare(targets, messages, columns, w)
+	if r := pickCompareResult(results, readLine); r != nil {
+		keepCompareResult(session, prompt, r)
+	}
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare -m modelA -m modelB [问题]",
+	Short: "把同一个问题发给多个模型并对比回复",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, _ := cmd.Flags().GetStringArray("model")
+		columns, _ := cmd.Flags().GetBool("columns")
+		keep, _ := cmd.Flags().GetInt("keep")
+		if len(targets) < 2 {
+			return errors.New(T("至少需要用 -m 指定两个模型或profile"))
+		}
+
+		messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: args[0]}}
+		results := RunCompare(targets, messages, columns, os.Stdout)
+
+		var failed int
+		for _, r := range results {
+			if r.Err != nil {
+				failed++
+			}
+		}
+		if keep > 0 && keep <= len(results) && results[keep-1].Err == nil {
+			keepCompareResult(NewSession(), args[0], results[keep-1])
+		}
+		if failed == len(results) {
+			return errors.New(T("所有模型调用均失败"))
+		}
+		return nil
+	},
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:  "/compare",
+		Usage: "[--columns] modelA modelB ... [-- 问题]",
+		Help:  "把同一个问题发给多个模型并对比回复",
+		Flags: []CommandFlag{
+			{Long: "--columns", Help: "全部完成后并排显示"},
+		},
+		Complete: completeCompare,
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleCompare(input, r.Session, r.ReadLine, stdout)
+		},
+	})
+
+	compareCmd.Flags().StringArrayP("model", "m", nil, "参与对比的模型名或profile名，可重复指定")
+	compareCmd.Flags().Bool("columns", false, "全部完成后并排显示")
+	compareCmd.Flags().Int("keep", 0, "把第N个回复保存为一次会话记录")
+	compareCmd.RegisterFlagCompletionFunc("model", cobraWords(compareTargets))
+	rootCmd.AddCommand(compareCmd)
+}
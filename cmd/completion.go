@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// completionWord 找到光标前正在输入的单词。返回当前管道命令的起始位置、单词的起始位置，
+// 以及单词是否跟在 > 或 >> 后面。引号和反斜杠转义中的空格不会拆分单词
+func completionWord(line string) (stage, start int, redirect bool) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ' ' || c == '\t' || c == '\n':
+			start = i + 1
+		case c == '|':
+			stage, start, redirect = i+1, i+1, false
+		case c == '>':
+			start, redirect = i+1, true
+		}
+	}
+	return stage, start, redirect
+}
+
+// unquoteWord 去掉单词中的引号和反斜杠转义，返回还没有闭合的引号
+func unquoteWord(s string) (string, rune) {
+	var word strings.Builder
+	var quote rune
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && strings.ContainsRune("\\\"$`", runes[i+1]) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		default:
+			word.WriteRune(r)
+		}
+	}
+	return word.String(), quote
+}
+
+// quoteWord 给补全的候选项加上引号或转义，quote为用户已经输入的引号。
+// 目录以"/"结尾时不闭合引号，方便继续补全下一级
+func quoteWord(s string, quote rune) string {
+	closing := string(quote)
+	if strings.HasSuffix(s, "/") {
+		closing = ""
+	}
+	switch quote {
+	case '\'':
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + closing
+	case '"':
+		var b strings.Builder
+		for _, r := range s {
+			if strings.ContainsRune("\\\"$`", r) {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+		}
+		return `"` + b.String() + closing
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(" \t\n\\'\"$|>", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// complete 补全命令名、命令选项和参数，无法判断时补全文件路径。
+// 正在输入的单词去掉引号和转义后再匹配，候选项按原来的引号方式加回引号
+func (r *REPL) complete(line string, pos int) (int, []string) {
+	stage, start, redirect := completionWord(line[:pos])
+	word, quote := unquoteWord(line[start:pos])
+
+	var candidates []string
+	if redirect {
+		candidates = completePaths(word)
+	} else {
+		candidates = r.completeArgs(line[stage:start], word)
+	}
+	for i, c := range candidates {
+		candidates[i] = quoteWord(c, quote)
+	}
+	return start, candidates
+}
+
+// completeArgs 按光标前已输入的内容补全word
+func (r *REPL) completeArgs(head, word string) []string {
+	args, err := splitArgs(head)
+	if err != nil {
+		args = strings.Fields(head)
+	}
+
+	if len(args) == 0 {
+		var names []string
+		for _, c := range commandList {
+			for _, name := range c.names() {
+				// 不带"/"的命令也可以加"/"调用
+				if strings.HasPrefix(word, "/") && !strings.HasPrefix(name, "/") {
+					name = "/" + name
+				}
+				if strings.HasPrefix(name, word) {
+					names = append(names, name)
+				}
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			return names
+		}
+		return completePaths(word)
+	}
+
+	c, _ := lookupCommand(args[0])
+	if c == nil {
+		return completePaths(word)
+	}
+	if strings.HasPrefix(word, "-") {
+		var flags []string
+		for _, f := range c.Flags {
+			for _, name := range []string{f.Short, f.Long} {
+				if name != "" && strings.HasPrefix(name, word) {
+					flags = append(flags, name)
+				}
+			}
+		}
+		return flags
+	}
+	// 需要参数的选项后面补全文件，例如 curl -o <Tab>
+	if f := c.flag(args[len(args)-1]); f != nil && f.Arg != "" {
+		return completePaths(word)
+	}
+	if c.Complete != nil {
+		return c.Complete(r, args[1:], word)
+	}
+	return nil
+}
+
+// completePaths 补全文件和目录，目录加上"/"，支持以~/开头的路径。
+// 按目录和文件名前缀匹配，word中的*、?、[不作为通配符；以.开头的文件只在前缀以.开头时补全
+func completePaths(word string) []string {
+	path, home := word, ""
+	if strings.HasPrefix(word, "~/") {
+		if dir, err := os.UserHomeDir(); err == nil {
+			home = dir
+			path = dir + word[1:]
+		}
+	}
+	dir, prefix := filepath.Split(path)
+	entries, err := os.ReadDir(cmp.Or(dir, "."))
+	if err != nil {
+		return nil
+	}
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		m := dir + name
+		if isDir(m) {
+			m += "/"
+		}
+		if home != "" {
+			m = "~" + strings.TrimPrefix(m, home)
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// completeFiles 补全文件和目录，用作内置命令的Complete
+func completeFiles(_ *REPL, _ []string, word string) []string {
+	return completePaths(word)
+}
+
+// completeWords 返回从固定候选中补全的函数
+func completeWords(words ...string) func(r *REPL, args []string, word string) []string {
+	return func(r *REPL, args []string, word string) []string {
+		return filterPrefix(words, word)
+	}
+}
+
+func filterPrefix(words []string, prefix string) []string {
+	var matches []string
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			matches = append(matches, w)
+		}
+	}
+	return matches
+}
+
+// completeExport 第一个参数补全导出格式，之后补全路径
+func completeExport(r *REPL, args []string, word string) []string {
+	if len(args) == 0 {
+		return filterPrefix(exportFormatNames(), word)
+	}
+	return completePaths(word)
+}
+
+// completeTemplate 第一个参数补全模板名，之后补全 @文件 形式的变量值
+func completeTemplate(r *REPL, args []string, word string) []string {
+	if len(args) == 0 {
+		return filterPrefix(ListTemplates(), word)
+	}
+	if key, value, ok := strings.Cut(word, "=@"); ok {
+		var matches []string
+		for _, path := range completePaths(value) {
+			matches = append(matches, key+"=@"+path)
+		}
+		return matches
+	}
+	return nil
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s'"|>]+`)
+
+// completeURL 补全历史记录中出现过的URL，以及文件路径
+func completeURL(r *REPL, args []string, word string) []string {
+	urls := historyURLs(r.History.Lines, word)
+	if word == "" {
+		return urls
+	}
+	return append(urls, completePaths(word)...)
+}
+
+// historyURLs 返回历史记录中以prefix开头的URL，越近使用的排在越前面
+func historyURLs(lines []string, prefix string) []string {
+	var urls []string
+	seen := map[string]bool{}
+	for i := len(lines) - 1; i >= 0; i-- {
+		for _, url := range urlPattern.FindAllString(lines[i], -1) {
+			if !seen[url] && strings.HasPrefix(url, prefix) {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// cobraURLs 命令行模式下补全历史记录中的URL，同时保留文件补全
+func cobraURLs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return historyURLs(LoadHistory().Lines, toComplete), cobra.ShellCompDirectiveDefault
+}
+
+// compareTargets 返回配置中的profile名和模型名
+func compareTargets() []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for name := range viper.GetStringMap("profiles") {
+		add(name)
+		add(viper.GetString("profiles." + name + ".model"))
+	}
+	add(viper.GetString("ai.model"))
+	sort.Strings(names)
+	return names
+}
+
+// completeCompare 在 -- 之前补全profile名和模型名
+func completeCompare(r *REPL, args []string, word string) []string {
+	for _, arg := range args {
+		if arg == "--" {
+			return nil
+		}
+	}
+	return filterPrefix(compareTargets(), word)
+}
+
+// cobraWords 返回cobra的参数补全函数，候选项由words生成
+func cobraWords(words func() []string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(words(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompletePaths(t *testing.T) {
+	t.Chdir(t.TempDir())
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, dir := range []string{"src", "src/[gen]", ".git", filepath.Join(home, "docs")} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"a*b.txt", "abc.txt", "a?.go", "src/main.go", ".env", filepath.Join(home, "notes.md")} {
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"", []string{"a*b.txt", "a?.go", "abc.txt", "src/"}},
+		{"a", []string{"a*b.txt", "a?.go", "abc.txt"}},
+		{"a*", []string{"a*b.txt"}},
+		{"a?", []string{"a?.go"}},
+		{"s", []string{"src/"}},
+		{"src/", []string{"src/[gen]/", "src/main.go"}},
+		{"src/[", []string{"src/[gen]/"}},
+		{"./ab", []string{"./abc.txt"}},
+		{".", []string{".env", ".git/"}},
+		{"~/", []string{"~/docs/", "~/notes.md"}},
+		{"~/n", []string{"~/notes.md"}},
+		{"missing/", nil},
+	}
+	for _, tt := range tests {
+		if got := completePaths(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completePaths(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
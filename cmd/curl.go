@@ -3,26 +3,30 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
 	"strings"
+
+	"github.com/spf13/cobra"
 )
 
+// CurlOptions curl命令的选项，flag标签用于/help和Tab补全
 type CurlOptions struct {
-	Data        string
-	Fail        bool
-	Include     bool
-	Output      string
-	RemoteName  bool
-	Silent      bool
-	UploadFile  string
-	User        string
-	UserAgent   string
-	Verbose     bool
-	AISummarize bool
+	Data        string `flag:"-d,--data" arg:"DATA" help:"以POST发送数据"`
+	Fail        bool   `flag:"-f,--fail" help:"HTTP错误时不输出内容"`
+	Include     bool   `flag:"-i,--include" help:"输出响应头"`
+	Output      string `flag:"-o,--output" arg:"FILE" help:"写入文件"`
+	RemoteName  bool   `flag:"-O,--remote-name" help:"以远程文件名保存"`
+	Silent      bool   `flag:"-s,--silent" help:"静默模式"`
+	UploadFile  string `flag:"-T,--upload-file" arg:"FILE" help:"上传文件"`
+	User        string `flag:"-u,--user" arg:"USER:PASS" help:"基本认证"`
+	UserAgent   string `flag:"-A,--user-agent" arg:"UA" help:"设置User-Agent"`
+	Verbose     bool   `flag:"-v,--verbose" help:"输出详细信息"`
+	AISummarize bool   `flag:"--ai" help:"让AI总结响应内容"`
 }
 
 func parseCurlArgs(args []string) (string, *CurlOptions, error) {
@@ -61,27 +65,37 @@ func parseCurlArgs(args []string) (string, *CurlOptions, error) {
 			options.AISummarize = true
 		default:
 			if strings.HasPrefix(arg, "-") {
-				return "", nil, fmt.Errorf("unknown option: %s", arg)
+				return "", nil, fmt.Errorf(T("未知选项: %s"), arg)
 			}
 			url = arg
 		}
 	}
 
 	if url == "" {
-		return "", nil, fmt.Errorf("URL is required")
+		return "", nil, errors.New(T("缺少URL"))
 	}
 
 	return url, options, nil
 }
 
-func HandleCurl(prompt string, processQuery func(string, bool)) {
-	args := strings.Fields(prompt)[1:] // Skip "curl"
+func HandleCurl(prompt string, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "curl"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
 	url, options, err := parseCurlArgs(args)
 	if err != nil {
-		fmt.Printf("Error parsing arguments: %v\n", err)
+		fmt.Printf(T("参数解析失败: %v\n"), err)
 		return
 	}
+	if err := runCurl(url, options, w, processQuery); err != nil {
+		fmt.Println(err)
+	}
+}
 
+// runCurl 按选项发送请求，REPL和命令行共用
+func runCurl(url string, options *CurlOptions, w io.Writer, processQuery func(string, bool)) error {
 	// Add http:// if missing
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 		url = "http://" + url
@@ -89,32 +103,29 @@ func HandleCurl(prompt string, processQuery func(string, bool)) {
 
 	var req *http.Request
 	var resp *http.Response
+	var err error
 
 	if options.UploadFile != "" {
 		file, err := os.Open(options.UploadFile)
 		if err != nil {
-			fmt.Printf("Error opening file: %v\n", err)
-			return
+			return fmt.Errorf(T("打开文件失败: %v"), err)
 		}
 		defer file.Close()
 
 		req, err = http.NewRequest("PUT", url, file)
 		if err != nil {
-			fmt.Printf("Error creating request: %v\n", err)
-			return
+			return fmt.Errorf(T("创建请求失败: %v"), err)
 		}
 	} else if options.Data != "" {
 		req, err = http.NewRequest("POST", url, bytes.NewBufferString(options.Data))
 		if err != nil {
-			fmt.Printf("Error creating request: %v\n", err)
-			return
+			return fmt.Errorf(T("创建请求失败: %v"), err)
 		}
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	} else {
 		req, err = http.NewRequest("GET", url, nil)
 		if err != nil {
-			fmt.Printf("Error creating request: %v\n", err)
-			return
+			return fmt.Errorf(T("创建请求失败: %v"), err)
 		}
 	}
 
@@ -152,26 +163,23 @@ func HandleCurl(prompt string, processQuery func(string, bool)) {
 	select {
 	case <-sigChan:
 		cancel()
-		fmt.Println("\n请求已取消")
-		return
+		fmt.Println()
+		return errors.New(T("请求已取消"))
 	case res := <-resultChan:
 		if res.err != nil {
-			fmt.Printf("Request failed: %v\n", res.err)
-			return
+			return fmt.Errorf(T("请求失败: %v"), res.err)
 		}
 		resp = res.resp
 		defer resp.Body.Close()
 	}
 
 	if options.Fail && resp.StatusCode >= 400 {
-		fmt.Printf("Request failed with status: %d\n", resp.StatusCode)
-		return
+		return fmt.Errorf(T("请求失败，状态码: %d"), resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Printf("Error reading response: %v\n", err)
-		return
+		return fmt.Errorf(T("读取响应失败: %v"), err)
 	}
 
 	output := ""
@@ -187,8 +195,7 @@ func HandleCurl(prompt string, processQuery func(string, bool)) {
 	if options.Output != "" {
 		err := os.WriteFile(options.Output, []byte(output), 0644)
 		if err != nil {
-			fmt.Printf("Error writing to file: %v\n", err)
-			return
+			return fmt.Errorf(T("写入文件失败: %v"), err)
 		}
 	} else if options.RemoteName {
 		filename := "index.html" // Default if can't determine from URL
@@ -201,16 +208,49 @@ func HandleCurl(prompt string, processQuery func(string, bool)) {
 		}
 		err := os.WriteFile(filename, []byte(output), 0644)
 		if err != nil {
-			fmt.Printf("Error writing to file: %v\n", err)
-			return
+			return fmt.Errorf(T("写入文件失败: %v"), err)
 		}
 	} else if !options.Silent {
-		fmt.Println(output)
+		fmt.Fprintln(w, output)
 	}
 
 	if options.AISummarize {
-		summaryPrompt := fmt.Sprintf("请总结以下内容:\n%s\n用中文简洁概括主要内容", string(body))
-		fmt.Println("AI总结:")
+		summaryPrompt, err := renderPrompt("curl-summary", map[string]string{"content": string(body)})
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		fmt.Fprintln(w, T("AI总结:"))
 		processQuery(summaryPrompt, true)
 	}
+	return nil
+}
+
+func newCurlCmd() *cobra.Command {
+	options := &CurlOptions{}
+	cmd := &cobra.Command{
+		Use:               "curl URL",
+		Short:             "发送HTTP请求，--ai 让AI总结响应",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: cobraURLs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCurl(args[0], options, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newCurlCmd())
+	registerCommand(&Command{
+		Name:     "curl",
+		Usage:    "[选项] URL",
+		Help:     "发送HTTP请求，--ai 让AI总结响应",
+		Flags:    optionFlags(CurlOptions{}),
+		Record:   true,
+		Complete: completeURL,
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleCurl(input, stdout, r.Query(stdout))
+		},
+	})
 }
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+)
+
+// DuOptions du命令的选项，flag标签用于/help和Tab补全
+type DuOptions struct {
+	Top         int  `flag:"-n,--top" arg:"N" help:"显示最大的N个目录和扩展名"`
+	MaxDepth    int  `flag:"-d,--max-depth" arg:"N" help:"只列出N层以内的目录，-1表示不限制，0表示只统计起始目录(与GNU du相同)，更深的文件仍计入统计"`
+	AISummarize bool `flag:"--ai" help:"让AI指出哪些可以安全删除，不会自动删除任何文件"`
+}
+
+func newDuOptions() *DuOptions {
+	return &DuOptions{Top: 10, MaxDepth: -1}
+}
+
+// duExt 一种扩展名的文件数和总大小
+type duExt struct {
+	name  string
+	count int
+	size  int64
+}
+
+// duWalker 并发遍历目录，按目录和扩展名汇总文件大小
+type duWalker struct {
+	options *DuOptions
+	sem     chan struct{} // 限制同时遍历目录的goroutine数量
+	mu      sync.Mutex
+	dirs    map[string]int64
+	exts    map[string]*duExt
+	failed  bool
+}
+
+// parseDuArgs 解析du的选项和路径
+func parseDuArgs(args []string) (*DuOptions, []string, error) {
+	options := newDuOptions()
+	number := func(name, value string, p *int) error {
+		n, err := strconv.Atoi(value)
+		// 只有层数可以是-1，表示不限制
+		if err != nil || n < 0 && (p != &options.MaxDepth || n != -1) {
+			return fmt.Errorf(T("%s 的值无效: %s"), name, value)
+		}
+		*p = n
+		return nil
+	}
+	values := map[string]*int{
+		"-n": &options.Top, "--top": &options.Top,
+		"-d": &options.MaxDepth, "--max-depth": &options.MaxDepth,
+	}
+
+	var paths []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return options, append(paths, args[i+1:]...), nil
+		case arg == "--ai":
+			options.AISummarize = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			name, value, hasValue := strings.Cut(arg, "=")
+			// 短选项的值可以紧跟在后面，如 -n20
+			if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+				name, value, hasValue = arg[:2], arg[2:], true
+			}
+			p, ok := values[name]
+			if !ok {
+				return nil, nil, fmt.Errorf(T("未知选项: %s"), arg)
+			}
+			if !hasValue {
+				i++
+				if i >= len(args) {
+					return nil, nil, fmt.Errorf(T("%s 需要一个参数"), name)
+				}
+				value = args[i]
+			}
+			if err := number(name, value, p); err != nil {
+				return nil, nil, err
+			}
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	return options, paths, nil
+}
+
+// HandleDu 处理du命令，统计磁盘占用
+func HandleDu(prompt string, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "du"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	options, paths, err := parseDuArgs(args)
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if err := runDu(paths, options, w, processQuery); err != nil && err != errReported {
+		fmt.Println(err)
+	}
+}
+
+// runDu 统计各个路径的磁盘占用，没有路径时统计当前目录。输出最大的目录和扩展名，
+// 大小按文件长度计算，不跟随符号链接。无法读取的目录输出错误后继续，有失败时返回errReported
+func runDu(paths []string, options *DuOptions, w io.Writer, processQuery func(string, bool)) error {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	d := &duWalker{
+		options: options,
+		sem:     make(chan struct{}, 4*runtime.NumCPU()),
+		dirs:    map[string]int64{},
+		exts:    map[string]*duExt{},
+	}
+
+	var total int64
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil {
+			d.fail(err)
+			continue
+		}
+		if !info.IsDir() {
+			d.addFiles(map[string]*duExt{duExtName(path): {count: 1, size: info.Size()}})
+			total += info.Size()
+			continue
+		}
+		total += d.walk(path, 0)
+	}
+
+	// 统计的根目录总是最大的，只在总计中显示
+	for _, path := range paths {
+		delete(d.dirs, path)
+	}
+	var out strings.Builder
+	if len(d.dirs) > 0 {
+		type dirSize struct {
+			path string
+			size int64
+		}
+		dirs := make([]dirSize, 0, len(d.dirs))
+		for path, size := range d.dirs {
+			dirs = append(dirs, dirSize{path, size})
+		}
+		sort.Slice(dirs, func(i, j int) bool {
+			if dirs[i].size != dirs[j].size {
+				return dirs[i].size > dirs[j].size
+			}
+			return dirs[i].path < dirs[j].path
+		})
+		out.WriteString(T("最大的目录:\n"))
+		for _, dir := range dirs[:min(options.Top, len(dirs))] {
+			fmt.Fprintf(&out, "%10s  %s\n", formatSize(dir.size), dir.path)
+		}
+		out.WriteString("\n")
+	}
+	if len(d.exts) > 0 {
+		exts := make([]*duExt, 0, len(d.exts))
+		for name, ext := range d.exts {
+			ext.name = name
+			exts = append(exts, ext)
+		}
+		sort.Slice(exts, func(i, j int) bool {
+			if exts[i].size != exts[j].size {
+				return exts[i].size > exts[j].size
+			}
+			return exts[i].name < exts[j].name
+		})
+		out.WriteString(T("最大的扩展名:\n"))
+		for _, ext := range exts[:min(options.Top, len(exts))] {
+			name := ext.name
+			if name == "" {
+				name = T("(无扩展名)")
+			}
+			fmt.Fprintf(&out, T("%10s  %s %d 个文件\n"), formatSize(ext.size), padRight(name, 12), ext.count)
+		}
+		out.WriteString("\n")
+	}
+	fmt.Fprintf(&out, T("总计: %s\n"), formatSize(total))
+	fmt.Fprint(w, out.String())
+
+	if options.AISummarize {
+		cleanupPrompt, err := renderPrompt("du-cleanup", map[string]string{"report": out.String()})
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		fmt.Fprintln(w, T("AI建议:"))
+		processQuery(cleanupPrompt, true)
+		fmt.Fprintln(w, T("du 不会删除任何文件，确认无误后可以用 !命令 自行删除"))
+	}
+	if d.failed {
+		return errReported
+	}
+	return nil
+}
+
+func (d *duWalker) fail(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fmt.Printf("du: %v\n", err)
+	d.failed = true
+}
+
+// walk 统计dir的总大小。子目录在有空闲名额时交给新的goroutine，否则在当前goroutine中遍历，
+// 避免所有goroutine都在等待名额
+func (d *duWalker) walk(dir string, depth int) int64 {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		d.fail(err)
+		return 0
+	}
+
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	exts := map[string]*duExt{}
+	for _, entry := range entries {
+		path := lsJoin(dir, entry.Name())
+		if entry.IsDir() {
+			select {
+			case d.sem <- struct{}{}:
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					total.Add(d.walk(path, depth+1))
+					<-d.sem
+				}()
+			default:
+				total.Add(d.walk(path, depth+1))
+			}
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			d.fail(err)
+			continue
+		}
+		name := duExtName(entry.Name())
+		if exts[name] == nil {
+			exts[name] = &duExt{}
+		}
+		exts[name].count++
+		exts[name].size += info.Size()
+		total.Add(info.Size())
+	}
+	wg.Wait()
+
+	d.addFiles(exts)
+	d.mu.Lock()
+	if d.options.MaxDepth < 0 || depth <= d.options.MaxDepth {
+		d.dirs[dir] = total.Load()
+	}
+	d.mu.Unlock()
+	return total.Load()
+}
+
+// addFiles 把一个目录中按扩展名汇总的结果合并到总数中
+func (d *duWalker) addFiles(exts map[string]*duExt) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for name, ext := range exts {
+		if d.exts[name] == nil {
+			d.exts[name] = &duExt{}
+		}
+		d.exts[name].count += ext.count
+		d.exts[name].size += ext.size
+	}
+}
+
+// duExtName 返回小写的扩展名，.bashrc这样以.开头的文件名没有扩展名
+func duExtName(name string) string {
+	name = filepath.Base(name)
+	if strings.LastIndex(name, ".") <= 0 {
+		return ""
+	}
+	return strings.ToLower(filepath.Ext(name))
+}
+
+func newDuCmd() *cobra.Command {
+	options := newDuOptions()
+	cmd := &cobra.Command{
+		Use:   "du [路径]...",
+		Short: "统计磁盘占用，--ai 让AI给出清理建议",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDu(args, options, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newDuCmd())
+	registerCommand(&Command{
+		Name:     "du",
+		Usage:    "[-n N] [-d N] [--ai] [路径...]",
+		Help:     "统计磁盘占用，--ai 让AI给出清理建议",
+		Flags:    optionFlags(DuOptions{}),
+		Record:   true,
+		Complete: completeFiles,
+		Operands: func(args []string) ([]string, error) {
+			_, paths, err := parseDuArgs(args)
+			return paths, err
+		},
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleDu(input, stdout, r.Query(stdout))
+		},
+	})
+}
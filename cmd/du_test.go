@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDuArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		top      int
+		maxDepth int
+		err      bool
+	}{
+		{nil, 10, -1, false},
+		{[]string{"-d", "0"}, 10, 0, false},
+		{[]string{"-d2", "-n", "3"}, 3, 2, false},
+		{[]string{"--max-depth=-1"}, 10, -1, false},
+		{[]string{"-d", "-2"}, 0, 0, true},
+		{[]string{"-n", "-1"}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		options, _, err := parseDuArgs(tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("parseDuArgs(%q) error = %v", tt.args, err)
+			continue
+		}
+		if !tt.err && (options.Top != tt.top || options.MaxDepth != tt.maxDepth) {
+			t.Errorf("parseDuArgs(%q) = top %d, depth %d, want %d, %d", tt.args, options.Top, options.MaxDepth, tt.top, tt.maxDepth)
+		}
+	}
+}
+
+func TestDuMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a", "b", "f.txt"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a, b := lsJoin(root, "a"), lsJoin(lsJoin(root, "a"), "b")
+
+	tests := []struct {
+		maxDepth int
+		listed   []string
+		hidden   []string
+	}{
+		{-1, []string{a, b}, nil},
+		{0, nil, []string{a, b}},
+		{1, []string{a}, []string{b}},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		options := newDuOptions()
+		options.MaxDepth = tt.maxDepth
+		if err := runDu([]string{root}, options, &out, nil); err != nil {
+			t.Fatal(err)
+		}
+		for _, dir := range tt.listed {
+			if !strings.Contains(out.String(), dir+"\n") {
+				t.Errorf("-d %d: %s not listed:\n%s", tt.maxDepth, dir, out.String())
+			}
+		}
+		for _, dir := range tt.hidden {
+			if strings.Contains(out.String(), dir+"\n") {
+				t.Errorf("-d %d: %s listed:\n%s", tt.maxDepth, dir, out.String())
+			}
+		}
+		// 更深的文件总是计入总计
+		if !strings.Contains(out.String(), formatSize(4)) {
+			t.Errorf("-d %d: total does not include deeper files:\n%s", tt.maxDepth, out.String())
+		}
+	}
+}
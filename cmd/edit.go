@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// editorCommand 返回 $VISUAL 或 $EDITOR 指定的编辑器命令，都未设置时使用系统默认编辑器
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// EditText 在编辑器中打开临时文件，编辑器退出后返回文件内容
+func EditText(initial string) (string, error) {
+	f, err := os.CreateTemp("", "ai-cli-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(initial)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	args := editorCommand()
+	editor := exec.Command(args[0], append(args[1:], f.Name())...)
+	editor.Stdin = os.Stdin
+	editor.Stdout = os.Stdout
+	editor.Stderr = os.Stderr
+	if err := editor.Run(); err != nil {
+		return "", fmt.Errorf(T("编辑器 %s 执行失败: %v"), args[0], err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// HandleEdit 处理REPL中的 /edit [初始内容]，在编辑器中撰写问题，保存退出后整体发送
+func HandleEdit(input string, processQuery func(string, bool)) {
+	initial := strings.TrimSpace(strings.TrimPrefix(input, "/edit"))
+	if initial != "" {
+		initial += "\n"
+	}
+	content, err := EditText(initial)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		return
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		fmt.Println(T("内容为空，已取消"))
+		return
+	}
+	processQuery(content, false)
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:  "/edit",
+		Usage: "[初始内容]",
+		Help:  "在$EDITOR中撰写问题，保存退出后发送",
+		Run:   func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleEdit(input, r.Query(stdout)) },
+	})
+}
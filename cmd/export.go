@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// stripANSI 去掉终端颜色等控制序列
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// exportFormat 一种导出格式，names中第一个是显示和补全时使用的名称，其余是别名
+type exportFormat struct {
+	names  []string
+	ext    string
+	export func(s *Session, w io.Writer) error
+}
+
+// exportFormats 支持的导出格式，ExportSession、ExportSessionFile和补全都以此为准
+var exportFormats = []exportFormat{
+	{[]string{"md", "markdown"}, ".md", exportMarkdown},
+	{[]string{"html"}, ".html", exportHTML},
+	{[]string{"jsonl"}, ".jsonl", exportJSONL},
+}
+
+// exportFormatNames 返回各导出格式的名称，不包括别名
+func exportFormatNames() []string {
+	var names []string
+	for _, f := range exportFormats {
+		names = append(names, f.names[0])
+	}
+	return names
+}
+
+// lookupExportFormat 按名称或别名查找导出格式
+func lookupExportFormat(name string) (*exportFormat, error) {
+	for i, f := range exportFormats {
+		if slices.Contains(f.names, name) {
+			return &exportFormats[i], nil
+		}
+	}
+	return nil, fmt.Errorf(T("不支持的导出格式: %s (可选 %s)"), name, strings.Join(exportFormatNames(), "|"))
+}
+
+// ExportSession 按指定格式把会话写入w
+func ExportSession(s *Session, format string, w io.Writer) error {
+	f, err := lookupExportFormat(format)
+	if err != nil {
+		return err
+	}
+	return f.export(s, w)
+}
+
+// ExportSessionFile 导出会话到文件，path为空时使用"<会话ID>.<格式>"
+func ExportSessionFile(s *Session, format, path string) (string, error) {
+	f, err := lookupExportFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		path = "ai-cli-" + s.ID + f.ext
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return path, f.export(s, file)
+}
+
+func exportJSONL(s *Session, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range s.Entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// codeFence 返回比内容中最长的反引号序列更长的围栏
+func codeFence(content string) string {
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+func formatUsage(u *TokenUsage) string {
+	s := fmt.Sprintf("prompt %d / completion %d / total %d", u.PromptTokens, u.CompletionTokens, u.TotalTokens)
+	if u.ReasoningTokens > 0 {
+		s += fmt.Sprintf(" (reasoning %d)", u.ReasoningTokens)
+	}
+	return s
+}
+
+func exportMarkdown(s *Session, w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, T("# AI-CLI 会话 %s\n\n"), s.ID)
+	if len(s.Entries) > 0 {
+		fmt.Fprintf(&b, T("- 开始时间: %s\n"), s.Entries[0].Time.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(&b, T("- 记录数: %d\n"), len(s.Entries))
+	total := s.TotalUsage()
+	fmt.Fprintf(&b, T("- Token 合计: %s\n"), formatUsage(&total))
+
+	for _, e := range s.Entries {
+		ts := e.Time.Format("15:04:05")
+		content := stripANSI(e.Content)
+		switch e.Role {
+		case EntryUser:
+			fmt.Fprintf(&b, T("\n## 用户 · %s\n\n%s\n"), ts, content)
+		case EntryAssistant:
+			fmt.Fprintf(&b, "\n## AI (%s) · %s\n\n", e.Model, ts)
+			if e.Reasoning != "" {
+				fmt.Fprintf(&b, T("<details>\n<summary>思考过程</summary>\n\n%s\n\n</details>\n\n"), e.Reasoning)
+			}
+			fmt.Fprintf(&b, "%s\n", content)
+		case EntryBuiltin:
+			fence := codeFence(content)
+			fmt.Fprintf(&b, T("\n## 命令 `%s` · %s\n\n%stext\n%s\n%s\n"), e.Command, ts, fence, strings.TrimRight(content, "\n"), fence)
+			if e.Model != "" {
+				fmt.Fprintf(&b, T("\nAI总结模型: %s\n"), e.Model)
+			}
+		}
+		if e.Usage != nil {
+			fmt.Fprintf(&b, "\n_tokens: %s_\n", formatUsage(e.Usage))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var sessionHTMLTemplate = template.Must(template.New("session").Funcs(template.FuncMap{
+	"usage": formatUsage,
+	"clean": stripANSI,
+	"t":     T,
+	"lang":  func() string { return language },
+}).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "AI-CLI 会话"}} {{.ID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+header { border-bottom: 1px solid #ddd; margin-bottom: 1.5em; }
+.entry { border: 1px solid #e3e3e3; border-radius: 6px; margin: 1em 0; padding: 0.8em 1em; }
+.user { background: #f4f8ff; }
+.assistant { background: #fbfbfb; }
+.builtin { background: #f7f7f2; }
+.meta { color: #777; font-size: 0.85em; margin-bottom: 0.5em; }
+.content { white-space: pre-wrap; word-wrap: break-word; }
+pre { background: #272822; color: #f8f8f2; padding: 0.8em; border-radius: 4px; overflow-x: auto; }
+code { font-family: Menlo, Consolas, monospace; }
+.reasoning { color: #888; margin-bottom: 0.6em; }
+.usage { color: #999; font-size: 0.8em; margin-top: 0.5em; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{t "AI-CLI 会话"}} {{.ID}}</h1>
+<p>{{t "记录数"}}: {{len .Entries}} · {{t "Token 合计"}}: {{usage .Total}}</p>
+</header>
+{{range .Entries}}<div class="entry {{.Role}}">
+<div class="meta">{{.Time.Format "2006-01-02 15:04:05"}} · {{if eq .Role "user"}}{{t "用户"}}{{else if eq .Role "assistant"}}AI ({{.Model}}){{else}}{{t "命令"}} <code>{{.Command}}</code>{{if .Model}} · {{t "AI总结模型"}} {{.Model}}{{end}}{{end}}</div>
+{{if .Reasoning}}<details class="reasoning"><summary>{{t "思考过程"}}</summary><div class="content">{{.Reasoning}}</div></details>
+{{end}}{{if eq .Role "builtin"}}<pre><code>{{clean .Content}}</code></pre>{{else}}<div class="content">{{clean .Content}}</div>{{end}}
+{{if .Usage}}<div class="usage">tokens: {{usage .Usage}}</div>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
+
+func exportHTML(s *Session, w io.Writer) error {
+	total := s.TotalUsage()
+	return sessionHTMLTemplate.Execute(w, struct {
+		ID      string
+		Entries []Entry
+		Total   *TokenUsage
+	}{s.ID, s.Entries, &total})
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestExportSessionRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	viper.Set("session.dir", dir)
+	defer viper.Set("session.dir", "")
+
+	s := NewSession()
+	s.AddUser("what is `go vet`?")
+	s.AddAssistant("m1", "It reports ```suspicious``` code.", "thinking", &TokenUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7})
+	s.BeginBuiltin("ls -l")
+	s.EndBuiltin("\033[34mdir\033[0m\n")
+
+	loaded, err := LoadSession(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := ExportSessionFile(loaded, "jsonl", filepath.Join(dir, "out.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	if !reflect.DeepEqual(entries, loaded.Entries) {
+		t.Errorf("jsonl export = %+v, want %+v", entries, loaded.Entries)
+	}
+
+	// 每种格式的名称和别名在两个入口中都可以使用，默认文件名使用对应的扩展名
+	t.Chdir(dir)
+	for _, format := range exportFormats {
+		for _, name := range format.names {
+			var b strings.Builder
+			if err := ExportSession(loaded, name, &b); err != nil {
+				t.Errorf("ExportSession(%q): %v", name, err)
+			}
+			path, err := ExportSessionFile(loaded, name, "")
+			if err != nil {
+				t.Errorf("ExportSessionFile(%q): %v", name, err)
+				continue
+			}
+			if path != "ai-cli-"+s.ID+format.ext {
+				t.Errorf("ExportSessionFile(%q) path = %s", name, path)
+			}
+			data, _ := os.ReadFile(path)
+			if string(data) != b.String() {
+				t.Errorf("ExportSessionFile(%q) differs from ExportSession", name)
+			}
+			if strings.Contains(b.String(), "\033[") {
+				t.Errorf("%s export contains ANSI escapes", name)
+			}
+		}
+	}
+
+	if err := ExportSession(loaded, "pdf", &strings.Builder{}); err == nil {
+		t.Error("ExportSession accepted pdf")
+	}
+	if _, err := ExportSessionFile(loaded, "pdf", ""); err == nil || !strings.Contains(err.Error(), "md|html|jsonl") {
+		t.Errorf("ExportSessionFile(pdf) error = %v", err)
+	}
+}
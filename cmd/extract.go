@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cobra"
+)
+
+// ExtractOptions extract命令的参数
+type ExtractOptions struct {
+	Mode    string // auto: 优先使用response_format，服务端不支持时退回提示词; schema; prompt
+	Retries int    // 校验失败后让模型修正的次数
+}
+
+const extractSystemPrompt = `你是信息抽取助手。请根据下面的JSON Schema从用户提供的文本中提取信息。
+只输出一个符合该Schema的JSON值，不要输出解释、注释或Markdown代码块。文本中没有的信息按Schema允许的方式留空或省略。
+
+JSON Schema:
+%s`
+
+// extractJSON 从模型回复中取出JSON部分，兼容包在代码块里或前后带说明文字的情况
+func extractJSON(reply string) string {
+	s := strings.TrimSpace(reply)
+	if strings.HasPrefix(s, "```") {
+		// 去掉 ```json 这一行和结尾的 ```
+		if i := strings.Index(s, "\n"); i >= 0 {
+			s = s[i+1:]
+		}
+		s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+	}
+	if json.Valid([]byte(s)) {
+		return s
+	}
+	start := strings.IndexAny(s, "{[")
+	end := strings.LastIndexAny(s, "}]")
+	if start >= 0 && end > start {
+		return s[start : end+1]
+	}
+	return s
+}
+
+// unsupportedResponseFormat 判断错误是否是服务端不支持response_format导致的：状态码为400或422，
+// 而且错误信息或出错的参数指向response_format。上下文超长、模型不存在等其他错误以及
+// schema本身无效时返回false，由调用方报告原始错误
+func unsupportedResponseFormat(err error) bool {
+	var status int
+	var text string
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status, text = apiErr.HTTPStatusCode, apiErr.Message
+		if apiErr.Param != nil {
+			text += " " + *apiErr.Param
+		}
+	case errors.As(err, &reqErr):
+		status, text = reqErr.HTTPStatusCode, string(reqErr.Body)
+	default:
+		return false
+	}
+	if status != http.StatusBadRequest && status != http.StatusUnprocessableEntity {
+		return false
+	}
+	text = strings.ToLower(text)
+	if strings.Contains(text, "invalid schema") {
+		return false
+	}
+	return strings.Contains(text, "response_format") || strings.Contains(text, "json_schema")
+}
+
+// schemaName 生成response_format要求的schema名称
+func schemaName(schema *jsonSchema) string {
+	title, _ := schema.root["title"].(string)
+	var b strings.Builder
+	for _, r := range title {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "extraction"
+	}
+	return b.String()
+}
+
+// RunExtract 按schema从文本中提取结构化数据，校验失败时把错误发回给模型修正
+func RunExtract(client *aiClient, schemaData []byte, input string, options *ExtractOptions) (string, error) {
+	schema, err := parseJSONSchema(schemaData)
+	if err != nil {
+		return "", err
+	}
+
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: fmt.Sprintf(extractSystemPrompt, schemaData)},
+		{Role: openai.ChatMessageRoleUser, Content: input},
+	}
+	useFormat := options.Mode != "prompt"
+
+	var errs []string
+	for attempt := 0; attempt <= options.Retries; attempt++ {
+		req := openai.ChatCompletionRequest{Messages: messages}
+		if useFormat {
+			req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+					Name:   schemaName(schema),
+					Schema: json.RawMessage(schemaData),
+				},
+			}
+		}
+
+		reply, err := client.complete(context.Background(), req)
+		if err != nil && useFormat && options.Mode == "auto" && unsupportedResponseFormat(err) {
+			fmt.Fprintf(os.Stderr, T("服务端不支持response_format，改用提示词约束: %v\n"), err)
+			useFormat = false
+			req.ResponseFormat = nil
+			reply, err = client.complete(context.Background(), req)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		result := extractJSON(reply.Content)
+		errs = schema.Validate([]byte(result))
+		if len(errs) == 0 {
+			var pretty bytes.Buffer
+			json.Indent(&pretty, []byte(result), "", "  ")
+			return pretty.String(), nil
+		}
+
+		if attempt < options.Retries {
+			fmt.Fprintf(os.Stderr, T("第 %d 次输出未通过校验(%d 处错误)，请求模型修正...\n"), attempt+1, len(errs))
+		}
+		messages = append(messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: reply.Content},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: "上面的输出没有通过JSON Schema校验:\n- " +
+				strings.Join(errs, "\n- ") + "\n请修正后只输出完整的JSON。"},
+		)
+	}
+	return "", fmt.Errorf(T("输出未通过schema校验:\n  %s"), strings.Join(errs, "\n  "))
+}
+
+var extractCmd = &cobra.Command{
+	Use:   "extract --schema schema.json [文件|-]",
+	Short: "按JSON Schema从文本中提取结构化数据",
+	Long: `按JSON Schema从文本中提取结构化数据，输出保证通过schema校验
+不指定文件或文件为"-"时读取标准输入`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schemaPath, _ := cmd.Flags().GetString("schema")
+		output, _ := cmd.Flags().GetString("output")
+		options := &ExtractOptions{}
+		options.Mode, _ = cmd.Flags().GetString("mode")
+		options.Retries, _ = cmd.Flags().GetInt("retries")
+		if options.Mode != "auto" && options.Mode != "schema" && options.Mode != "prompt" {
+			return errors.New(T("--mode 只能是 auto|schema|prompt"))
+		}
+
+		schemaData, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return err
+		}
+		path := "-"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		input, err := readInput(path)
+		if err != nil {
+			return err
+		}
+
+		client, err := loadAIClient()
+		if err != nil {
+			return err
+		}
+		result, err := RunExtract(client, schemaData, input, options)
+		if err != nil {
+			return err
+		}
+
+		if output != "" {
+			return os.WriteFile(output, []byte(result+"\n"), 0644)
+		}
+		fmt.Println(result)
+		return nil
+	},
+}
+
+func init() {
+	extractCmd.Flags().StringP("schema", "s", "", "JSON Schema文件")
+	extractCmd.Flags().StringP("output", "o", "", "输出文件，默认写到标准输出")
+	extractCmd.Flags().String("mode", "auto", "约束方式: auto|schema|prompt")
+	extractCmd.Flags().Int("retries", 2, "校验失败后的修正次数")
+	extractCmd.MarkFlagRequired("schema")
+	rootCmd.AddCommand(extractCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestUnsupportedResponseFormat(t *testing.T) {
+	param := func(s string) *string { return &s }
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unsupported parameter", &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "Unsupported parameter: 'response_format'"}, true},
+		{"param", &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "not supported with this model", Param: param("response_format")}, true},
+		{"unprocessable", &openai.APIError{HTTPStatusCode: http.StatusUnprocessableEntity, Message: "json_schema is not supported"}, true},
+		{"wrapped", fmt.Errorf("extract: %w", &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Param: param("response_format")}), true},
+		{"request error body", &openai.RequestError{HTTPStatusCode: http.StatusBadRequest, Body: []byte(`{"detail":"unknown field response_format"}`)}, true},
+		{"context length", &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "This model's maximum context length is 8192 tokens", Param: param("messages")}, false},
+		{"unknown model", &openai.APIError{HTTPStatusCode: http.StatusNotFound, Message: "The model `gpt-x` does not exist"}, false},
+		{"invalid schema", &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "Invalid schema for response_format 'extraction': 'required' is not an array", Param: param("response_format")}, false},
+		{"bad request without details", &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "bad request"}, false},
+		{"server error", &openai.APIError{HTTPStatusCode: http.StatusInternalServerError, Message: "response_format failed"}, false},
+		{"other error", errors.New("response_format"), false},
+	}
+	for _, tt := range tests {
+		if got := unsupportedResponseFormat(tt.err); got != tt.want {
+			t.Errorf("%s: unsupportedResponseFormat = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// FindOptions find命令的条件，与GNU find一样条件以单个-开头，flag标签用于/help和Tab补全
+type FindOptions struct {
+	Name        string `flag:"-name" arg:"GLOB" help:"文件名匹配GLOB"`
+	IName       string `flag:"-iname" arg:"GLOB" help:"文件名匹配GLOB，忽略大小写"`
+	Type        string `flag:"-type" arg:"f|d|l" help:"文件类型: f普通文件，d目录，l符号链接，可以用逗号分隔多个"`
+	Size        string `flag:"-size" arg:"[+-]N[ckMG]" help:"文件大小，+表示大于，-表示小于，默认单位为512字节的块"`
+	Mtime       string `flag:"-mtime" arg:"[+-]N" help:"修改时间在N天前，+表示超过N天，-表示N天以内"`
+	MaxDepth    int    `flag:"-maxdepth" arg:"N" help:"最多进入N层目录"`
+	AISummarize bool   `flag:"--ai" help:"让AI总结或归类找到的文件"`
+	Ask         string `flag:"--ask" arg:"QUESTION" help:"对找到的文件向AI提问，隐含--ai"`
+}
+
+func newFindOptions() *FindOptions {
+	return &FindOptions{MaxDepth: -1}
+}
+
+// parseFindArgs 解析find的路径和条件，第一个以-开头的参数之前都是路径
+func parseFindArgs(args []string) (*FindOptions, []string, error) {
+	options := newFindOptions()
+	values := map[string]*string{
+		"-name": &options.Name, "-iname": &options.IName, "-type": &options.Type,
+		"-size": &options.Size, "-mtime": &options.Mtime, "--ask": &options.Ask,
+	}
+
+	var paths []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		paths = append(paths, args[0])
+		args = args[1:]
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--ai" {
+			options.AISummarize = true
+			continue
+		}
+		if _, ok := values[arg]; !ok && arg != "-maxdepth" {
+			return nil, nil, fmt.Errorf(T("未知的条件: %s"), arg)
+		}
+		i++
+		if i >= len(args) {
+			return nil, nil, fmt.Errorf(T("%s 需要一个参数"), arg)
+		}
+		if arg == "-maxdepth" {
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 0 {
+				return nil, nil, fmt.Errorf(T("无效的层数: %s"), args[i])
+			}
+			options.MaxDepth = n
+			continue
+		}
+		*values[arg] = args[i]
+	}
+	return options, paths, nil
+}
+
+// HandleFind 处理find命令，按条件查找文件
+func HandleFind(prompt string, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "find"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	options, paths, err := parseFindArgs(args)
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if err := runFind(paths, options, w, processQuery); err != nil && err != errReported {
+		fmt.Println(err)
+	}
+}
+
+// findNumber 解析 +N、-N、N 形式的数字，返回比较方向(1大于，-1小于，0等于)和数值
+func findNumber(s string) (int, string, error) {
+	cmp, n := 0, s
+	switch {
+	case strings.HasPrefix(s, "+"):
+		cmp, n = 1, s[1:]
+	case strings.HasPrefix(s, "-"):
+		cmp, n = -1, s[1:]
+	}
+	if n == "" {
+		return 0, "", fmt.Errorf(T("无效的数值: %s"), s)
+	}
+	return cmp, n, nil
+}
+
+// compareFind 按findNumber返回的方向比较
+func compareFind(cmp int, value, n int64) bool {
+	switch cmp {
+	case 1:
+		return value > n
+	case -1:
+		return value < n
+	default:
+		return value == n
+	}
+}
+
+// findMatcher 编译后的查找条件
+type findMatcher struct {
+	options  *FindOptions
+	types    string
+	sizeCmp  int
+	size     int64
+	unit     int64
+	mtimeCmp int
+	mtime    int64
+	now      time.Time
+}
+
+func newFindMatcher(options *FindOptions) (*findMatcher, error) {
+	m := &findMatcher{options: options, now: time.Now()}
+	for _, t := range strings.Split(options.Type, ",") {
+		if t != "" && t != "f" && t != "d" && t != "l" {
+			return nil, fmt.Errorf(T("-type 的值无效: %s (可选 f|d|l)"), options.Type)
+		}
+		m.types += t
+	}
+	for _, glob := range []string{options.Name, options.IName} {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf(T("无效的通配符: %s"), glob)
+		}
+	}
+	if options.Size != "" {
+		cmp, s, err := findNumber(options.Size)
+		if err != nil {
+			return nil, err
+		}
+		// 与GNU find一样没有单位时以512字节的块计算
+		m.unit = 512
+		units := map[byte]int64{'c': 1, 'k': 1 << 10, 'M': 1 << 20, 'G': 1 << 30}
+		if unit, ok := units[s[len(s)-1]]; ok {
+			m.unit, s = unit, s[:len(s)-1]
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf(T("-size 的值无效: %s"), options.Size)
+		}
+		m.sizeCmp, m.size = cmp, n
+	}
+	if options.Mtime != "" {
+		cmp, s, err := findNumber(options.Mtime)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf(T("-mtime 的值无效: %s"), options.Mtime)
+		}
+		m.mtimeCmp, m.mtime = cmp, n
+	}
+	return m, nil
+}
+
+// match 判断文件是否满足所有条件
+func (m *findMatcher) match(d fs.DirEntry) (bool, error) {
+	name := d.Name()
+	if ok, _ := filepath.Match(m.options.Name, name); m.options.Name != "" && !ok {
+		return false, nil
+	}
+	if ok, _ := filepath.Match(strings.ToLower(m.options.IName), strings.ToLower(name)); m.options.IName != "" && !ok {
+		return false, nil
+	}
+	if m.types != "" {
+		t := "f"
+		switch {
+		case d.IsDir():
+			t = "d"
+		case d.Type()&fs.ModeSymlink != 0:
+			t = "l"
+		case !d.Type().IsRegular():
+			t = ""
+		}
+		if t == "" || !strings.Contains(m.types, t) {
+			return false, nil
+		}
+	}
+	if m.options.Size == "" && m.options.Mtime == "" {
+		return true, nil
+	}
+	info, err := d.Info()
+	if err != nil {
+		return false, err
+	}
+	// 大小向上取整到单位，与GNU find一样 -size -1M 只匹配空文件
+	if m.options.Size != "" && !compareFind(m.sizeCmp, int64(math.Ceil(float64(info.Size())/float64(m.unit))), m.size) {
+		return false, nil
+	}
+	if m.options.Mtime != "" {
+		days := int64(m.now.Sub(info.ModTime()) / (24 * time.Hour))
+		if !compareFind(m.mtimeCmp, days, m.mtime) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// runFind 在各个路径下查找满足条件的文件，每行输出一个路径，没有路径时查找当前目录。
+// 无法访问的路径输出错误后继续，有失败时返回errReported
+func runFind(paths []string, options *FindOptions, w io.Writer, processQuery func(string, bool)) error {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	m, err := newFindMatcher(options)
+	if err != nil {
+		return err
+	}
+
+	var out strings.Builder
+	failed := false
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				fmt.Printf("find: %v\n", err)
+				failed = true
+				return nil
+			}
+			ok, err := m.match(d)
+			if err != nil {
+				fmt.Printf("find: %v\n", err)
+				failed = true
+			}
+			if ok {
+				// WalkDir会去掉开头的./，与GNU find一样按输入的路径显示
+				if rel, err := filepath.Rel(root, path); err == nil && rel != "." {
+					path = lsJoin(root, rel)
+				}
+				out.WriteString(path + "\n")
+			}
+			if d.IsDir() && options.MaxDepth >= 0 && findDepth(root, path) >= options.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+	fmt.Fprint(w, out.String())
+
+	if (options.AISummarize || options.Ask != "") && out.Len() > 0 {
+		vars := map[string]string{"content": out.String()}
+		if options.Ask != "" {
+			vars["question"] = options.Ask
+		}
+		summaryPrompt, err := renderPrompt("search-summary", vars)
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		fmt.Fprintln(w, T("AI总结:"))
+		processQuery(summaryPrompt, true)
+	}
+	if failed {
+		return errReported
+	}
+	return nil
+}
+
+// findDepth 返回path相对root的层数，root本身为0
+func findDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// parseInheritedFlags 关闭cobra的选项解析后，根命令的全局选项(--lang、--profile等)也不会被解析，
+// 这里取出并设置这些选项，返回其余参数
+func parseInheritedFlags(cmd *cobra.Command, args []string) ([]string, error) {
+	flags := cmd.InheritedFlags()
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		f := flags.Lookup(name)
+		if !strings.HasPrefix(arg, "--") || f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if f.NoOptDefVal != "" {
+				value = f.NoOptDefVal
+			} else {
+				i++
+				if i >= len(args) {
+					return nil, fmt.Errorf(T("%s 需要一个参数"), arg)
+				}
+				value = args[i]
+			}
+		}
+		if err := flags.Set(name, value); err != nil {
+			return nil, err
+		}
+	}
+	// 界面语言在解析选项之前已经确定，--lang 需要重新应用
+	if flags.Changed("lang") {
+		languageApplied = false
+		applyLanguage()
+	}
+	return rest, nil
+}
+
+func newFindCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "find [路径...] [条件...]",
+		Short: "按名称、类型、大小和修改时间查找文件，--ai 让AI总结结果",
+		Long: `按名称、类型、大小和修改时间查找文件，--ai 让AI总结结果
+条件: -name GLOB, -iname GLOB, -type f|d|l, -size [+-]N[ckMG], -mtime [+-]N, -maxdepth N, --ai, --ask QUESTION`,
+		// 条件以单个-开头，由parseFindArgs解析
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			args, err := parseInheritedFlags(cmd, args)
+			if err != nil {
+				return err
+			}
+			for _, arg := range args {
+				if arg == "-h" || arg == "--help" {
+					return cmd.Help()
+				}
+			}
+			options, paths, err := parseFindArgs(args)
+			if err != nil {
+				return err
+			}
+			return runFind(paths, options, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(newFindCmd())
+	registerCommand(&Command{
+		Name:     "find",
+		Usage:    "[路径...] [-name GLOB] [-type f|d|l] [-size N] [-mtime N] [--ai]",
+		Help:     "按名称、类型、大小和修改时间查找文件，--ai 让AI总结结果",
+		Flags:    optionFlags(FindOptions{}),
+		Record:   true,
+		Complete: completeFiles,
+		Operands: func(args []string) ([]string, error) {
+			_, paths, err := parseFindArgs(args)
+			return paths, err
+		},
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleFind(input, stdout, r.Query(stdout))
+		},
+	})
+}
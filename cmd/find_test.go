@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFindArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		options FindOptions
+		paths   []string
+	}{
+		{nil, FindOptions{MaxDepth: -1}, nil},
+		{[]string{".", "src", "-name", "*.go"}, FindOptions{Name: "*.go", MaxDepth: -1}, []string{".", "src"}},
+		{[]string{"-iname", "README*", "-type", "f,l"}, FindOptions{IName: "README*", Type: "f,l", MaxDepth: -1}, nil},
+		{[]string{"-size", "+1M", "-mtime", "-7"}, FindOptions{Size: "+1M", Mtime: "-7", MaxDepth: -1}, nil},
+		{[]string{"-maxdepth", "0"}, FindOptions{MaxDepth: 0}, nil},
+		{[]string{"logs", "--ask", "which is largest?"}, FindOptions{Ask: "which is largest?", MaxDepth: -1}, []string{"logs"}},
+		{[]string{"--ai", "-name", "-x"}, FindOptions{Name: "-x", AISummarize: true, MaxDepth: -1}, nil},
+	}
+	for _, tt := range tests {
+		options, paths, err := parseFindArgs(tt.args)
+		if err != nil {
+			t.Errorf("parseFindArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if *options != tt.options || !reflect.DeepEqual(paths, tt.paths) {
+			t.Errorf("parseFindArgs(%q) = %+v, %q, want %+v, %q", tt.args, *options, paths, tt.options, tt.paths)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"-print"},
+		{".", "-name"},
+		{"-maxdepth", "-1"},
+		{"-maxdepth", "x"},
+		{".", "-name", "*.go", "src"},
+	} {
+		if _, _, err := parseFindArgs(args); err == nil {
+			t.Errorf("parseFindArgs(%q) accepted invalid arguments", args)
+		}
+	}
+}
+
+func TestFindMatcher(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	files := []struct {
+		name string
+		size int
+		age  time.Duration
+	}{
+		{"empty", 0, 0},
+		{"one", 1, 0},
+		{"block", 512, 0},
+		{"block1", 513, 0},
+		{"kilo", 1024, 0},
+		{"yesterday", 0, 36 * time.Hour},
+		{"old", 0, 10 * 24 * time.Hour},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, make([]byte, f.size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-f.age), now.Add(-f.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		options FindOptions
+		want    []string
+	}{
+		// 大小向上取整到单位
+		{FindOptions{Size: "1"}, []string{"block", "one"}},
+		{FindOptions{Size: "2"}, []string{"block1", "kilo"}},
+		{FindOptions{Size: "-1M"}, []string{"empty", "old", "yesterday"}},
+		{FindOptions{Size: "-1k"}, []string{"empty", "old", "yesterday"}},
+		{FindOptions{Size: "1k"}, []string{"block", "block1", "kilo", "one"}},
+		{FindOptions{Size: "+512c"}, []string{"block1", "kilo"}},
+		{FindOptions{Size: "512c"}, []string{"block"}},
+		// 修改时间按整天计算，不足一天算作0
+		{FindOptions{Mtime: "0"}, []string{"block", "block1", "empty", "kilo", "one"}},
+		{FindOptions{Mtime: "1"}, []string{"yesterday"}},
+		{FindOptions{Mtime: "-1"}, []string{"block", "block1", "empty", "kilo", "one"}},
+		{FindOptions{Mtime: "+1"}, []string{"old"}},
+		{FindOptions{Mtime: "+0", Name: "y*"}, []string{"yesterday"}},
+		{FindOptions{IName: "BLOCK?"}, []string{"block1"}},
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		m, err := newFindMatcher(&tt.options)
+		if err != nil {
+			t.Errorf("newFindMatcher(%+v): %v", tt.options, err)
+			continue
+		}
+		m.now = now
+		var got []string
+		for _, d := range entries {
+			if ok, err := m.match(d); err != nil {
+				t.Fatal(err)
+			} else if ok {
+				got = append(got, d.Name())
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("find %+v = %q, want %q", tt.options, got, tt.want)
+		}
+	}
+
+	for _, options := range []FindOptions{
+		{Type: "x"},
+		{Name: "[a"},
+		{Size: "+"},
+		{Size: "1x"},
+		{Mtime: "1d"},
+	} {
+		if _, err := newFindMatcher(&options); err == nil {
+			t.Errorf("newFindMatcher(%+v) accepted an invalid condition", options)
+		}
+	}
+}
+
+func TestRunFindMaxDepth(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"top.txt", "a/mid.txt", "a/b/low.txt"} {
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		paths   []string
+		options FindOptions
+		want    string
+	}{
+		{nil, FindOptions{MaxDepth: 0}, ".\n"},
+		{[]string{"./"}, FindOptions{MaxDepth: 1, Type: "f"}, "./top.txt\n"},
+		{[]string{"a"}, FindOptions{MaxDepth: 1}, "a\na/b\na/mid.txt\n"},
+		{[]string{"."}, FindOptions{MaxDepth: -1, Name: "*.txt"}, "./a/b/low.txt\n./a/mid.txt\n./top.txt\n"},
+		{[]string{"a/b/low.txt"}, FindOptions{MaxDepth: 0}, "a/b/low.txt\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := runFind(tt.paths, &tt.options, &b, nil); err != nil {
+			t.Errorf("runFind(%q, %+v): %v", tt.paths, tt.options, err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("runFind(%q, %+v) = %q, want %q", tt.paths, tt.options, b.String(), tt.want)
+		}
+	}
+}
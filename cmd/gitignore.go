@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule .gitignore中的一条规则
+type ignoreRule struct {
+	base    string         // .gitignore所在的目录
+	re      *regexp.Regexp // 匹配相对base的路径，分隔符为/
+	negate  bool           // 以!开头，重新包含之前被忽略的文件
+	dirOnly bool           // 以/结尾，只匹配目录
+}
+
+// readGitignore 读取dir下的.gitignore，文件不存在时返回nil。无法解析的规则会被跳过
+func readGitignore(dir string) []ignoreRule {
+	f, err := os.Open(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: dir}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		if rule.re, err = gitignorePattern(line); err != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// gitignorePattern 把.gitignore的模式转换为正则。模式中间有/时相对.gitignore所在目录匹配，
+// 否则匹配任意层级的文件名。支持 *、?、[...] 和 **
+func gitignorePattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// gitignoreRules 返回dir及其上级目录直到git仓库根目录的.gitignore规则，上级目录的规则在前。
+// dir不在git仓库中时只读取dir自己的.gitignore
+func gitignoreRules(dir string) []ignoreRule {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	dirs := []string{abs}
+	for d := abs; ; {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			dirs = dirs[:1]
+			break
+		}
+		d = parent
+		dirs = append(dirs, d)
+	}
+
+	var rules []ignoreRule
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rules = append(rules, readGitignore(dirs[i])...)
+	}
+	return rules
+}
+
+// gitignored 判断绝对路径path是否被忽略，与git一样后面的规则优先
+func gitignored(rules []ignoreRule, path string, isDir bool) bool {
+	ignored := false
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		rel, err := filepath.Rel(r.base, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if r.re.MatchString(filepath.ToSlash(rel)) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// walkGitignored 与filepath.WalkDir一样遍历root，但跳过.git目录和.gitignore忽略的文件。
+// root本身总会传给fn
+func walkGitignored(root string, fn fs.WalkDirFunc) error {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	// 每个目录下的文件适用的规则，包括上级目录和该目录自己的.gitignore
+	rules := map[string][]ignoreRule{abs: gitignoreRules(abs)}
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == root {
+			return fn(path, d, err)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fn(path, d, err)
+		}
+		absPath := filepath.Join(abs, rel)
+		parent := rules[filepath.Dir(absPath)]
+		if d.Name() == ".git" || gitignored(parent, absPath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			rules[absPath] = append(parent[:len(parent):len(parent)], readGitignore(absPath)...)
+		}
+		return fn(path, d, nil)
+	})
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitignorePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.log", "app.log", true},
+		{"*.log", "logs/app.log", true},
+		{"*.log", "app.log.1", false},
+		{"build", "build", true},
+		{"build", "src/build", true},
+		{"/build", "build", true},
+		{"/build", "src/build", false},
+		{"doc/*.txt", "doc/a.txt", true},
+		{"doc/*.txt", "doc/sub/a.txt", false},
+		{"doc/*.txt", "src/doc/a.txt", false},
+		{"**/tmp", "tmp", true},
+		{"**/tmp", "a/b/tmp", true},
+		{"logs/**", "logs/a/b.log", true},
+		{"logs/**", "logs", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"file?", "file/", false},
+		{"[abc].go", "b.go", true},
+		{"[abc].go", "d.go", false},
+		{"[!abc].go", "d.go", true},
+		{"[!abc].go", "a.go", false},
+		{"[a-c]x", "bx", true},
+		{`\*.go`, "*.go", true},
+		{`\*.go`, "main.go", false},
+		{"[unclosed", "[unclosed", true},
+		{"a+b.txt", "a+b.txt", true},
+		{"a+b.txt", "aab.txt", false},
+	}
+	for _, tt := range tests {
+		re, err := gitignorePattern(tt.pattern)
+		if err != nil {
+			t.Errorf("gitignorePattern(%q): %v", tt.pattern, err)
+			continue
+		}
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Errorf("gitignorePattern(%q) matches %q = %v, want %v", tt.pattern, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestGitignored(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(".gitignore", "# comment\n*.log\n!keep.log\nout/\n\\#hash\n/root.txt\n")
+	write("sub/.gitignore", "*.txt\n!keep.log\ndebug.log\n")
+
+	// sub下的规则在根目录的规则之后，优先级更高
+	rules := gitignoreRules(filepath.Join(dir, "sub"))
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"keep.log", false, false},
+		{"out", true, true},
+		{"out", false, false},
+		{"#hash", false, true},
+		{"root.txt", false, true},
+		{"sub/root.txt", false, true},
+		{"sub/a.txt", false, true},
+		{"sub/a.go", false, false},
+		{"sub/debug.log", false, true},
+		{"sub/keep.log", false, false},
+		{"sub/deep/out", true, true},
+	}
+	for _, tt := range tests {
+		if got := gitignored(rules, filepath.Join(dir, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("gitignored(%q, dir %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+
+	write("out/x.go", "")
+	write("sub/a.go", "")
+	write("sub/a.txt", "")
+	write("app.log", "")
+	// 忽略的目录整个跳过，.git目录不会被遍历
+	var visited []string
+	err := walkGitignored(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		visited = append(visited, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", ".gitignore", "sub", "sub/.gitignore", "sub/a.go"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("walkGitignored visited %q, want %q", visited, want)
+	}
+}
@@ -0,0 +1,406 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// GrepOptions grep命令的选项，flag标签用于/help和Tab补全
+type GrepOptions struct {
+	Recursive        bool     `flag:"-r,--recursive" help:"递归搜索目录，没有指定路径时搜索当前目录"`
+	IgnoreCase       bool     `flag:"-i,--ignore-case" help:"忽略大小写"`
+	LineNumber       bool     `flag:"-n,--line-number" help:"显示行号"`
+	Context          int      `flag:"-C,--context" arg:"N" help:"显示匹配行前后N行"`
+	FixedStrings     bool     `flag:"-F,--fixed-strings" help:"把模式当作普通字符串而不是正则"`
+	InvertMatch      bool     `flag:"-v,--invert-match" help:"显示不匹配的行"`
+	FilesWithMatches bool     `flag:"-l,--files-with-matches" help:"只显示有匹配的文件名"`
+	Include          []string `flag:"--include" arg:"GLOB" help:"只搜索文件名匹配GLOB的文件，可以重复"`
+	Exclude          []string `flag:"--exclude" arg:"GLOB" help:"跳过名称匹配GLOB的文件和目录，可以重复"`
+	NoIgnore         bool     `flag:"--no-ignore" help:"不按.gitignore过滤"`
+	Color            string   `flag:"--color" arg:"WHEN" help:"高亮匹配内容: always|auto|never"`
+	AISummarize      bool     `flag:"--ai" help:"让AI总结或归类匹配结果"`
+	Ask              string   `flag:"--ask" arg:"QUESTION" help:"对匹配结果向AI提问，隐含--ai"`
+}
+
+func newGrepOptions() *GrepOptions {
+	return &GrepOptions{Color: "auto"}
+}
+
+// GNU grep的默认颜色
+const (
+	grepColorMatch = "\033[01;31m"
+	grepColorFile  = "\033[35m"
+	grepColorLine  = "\033[32m"
+	grepColorSep   = "\033[36m"
+)
+
+// parseGrepArgs 解析grep的选项、模式和文件。与GNU grep一样选项可以写在模式和文件之后，
+// 短选项可以合并写，如 -rn、-C2
+func parseGrepArgs(args []string) (*GrepOptions, string, []string, error) {
+	options := newGrepOptions()
+	flags := map[string]*bool{
+		"-r": &options.Recursive, "--recursive": &options.Recursive,
+		"-i": &options.IgnoreCase, "--ignore-case": &options.IgnoreCase,
+		"-n": &options.LineNumber, "--line-number": &options.LineNumber,
+		"-F": &options.FixedStrings, "--fixed-strings": &options.FixedStrings,
+		"-v": &options.InvertMatch, "--invert-match": &options.InvertMatch,
+		"-l": &options.FilesWithMatches, "--files-with-matches": &options.FilesWithMatches,
+		"--no-ignore": &options.NoIgnore, "--ai": &options.AISummarize,
+	}
+	// 需要参数的选项
+	values := map[string]func(string) error{
+		"--context": func(v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf(T("无效的行数: %s"), v)
+			}
+			options.Context = n
+			return nil
+		},
+		"--include": func(v string) error { options.Include = append(options.Include, v); return nil },
+		"--exclude": func(v string) error { options.Exclude = append(options.Exclude, v); return nil },
+		"--color":   func(v string) error { options.Color = v; return nil },
+		"--ask":     func(v string) error { options.Ask = v; return nil },
+	}
+	values["-C"] = values["--context"]
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case arg == "--color":
+			options.Color = "always"
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg, "=")
+			if p, ok := flags[name]; ok && !hasValue {
+				*p = true
+				continue
+			}
+			set, ok := values[name]
+			if !ok {
+				return nil, "", nil, fmt.Errorf(T("未知选项: %s"), arg)
+			}
+			if !hasValue {
+				i++
+				if i >= len(args) {
+					return nil, "", nil, fmt.Errorf(T("%s 需要一个参数"), name)
+				}
+				value = args[i]
+			}
+			if err := set(value); err != nil {
+				return nil, "", nil, err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for j := 1; j < len(arg); j++ {
+				name := "-" + arg[j:j+1]
+				if p, ok := flags[name]; ok {
+					*p = true
+					continue
+				}
+				set, ok := values[name]
+				if !ok {
+					return nil, "", nil, fmt.Errorf(T("未知选项: %s"), name)
+				}
+				// 参数可以紧跟在选项后面，也可以是下一个参数
+				value := arg[j+1:]
+				if value == "" {
+					i++
+					if i >= len(args) {
+						return nil, "", nil, fmt.Errorf(T("%s 需要一个参数"), name)
+					}
+					value = args[i]
+				}
+				if err := set(value); err != nil {
+					return nil, "", nil, err
+				}
+				break
+			}
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return nil, "", nil, errors.New(T("缺少搜索模式"))
+	}
+	return options, positional[0], positional[1:], nil
+}
+
+// HandleGrep 处理grep命令，在文件或管道输入中搜索
+func HandleGrep(prompt string, stdin io.Reader, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "grep"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	options, pattern, paths, err := parseGrepArgs(args)
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if err := runGrep(pattern, paths, options, stdin, w, processQuery); err != nil && err != errReported {
+		fmt.Println(err)
+	}
+}
+
+// grepper 按选项搜索文件并把结果写入out
+type grepper struct {
+	options  *GrepOptions
+	re       *regexp.Regexp
+	stdin    io.Reader
+	out      strings.Builder
+	color    bool
+	names    bool // 是否在每行前显示文件名
+	printed  bool // 是否已经输出过匹配，用于在上下文分组之间输出--
+	matched  bool
+	failed   bool
+	explicit bool // 当前文件是否是直接指定的，递归时遇到的二进制文件不提示
+}
+
+// runGrep 在各个路径中搜索pattern。没有路径时 -r 搜索当前目录，否则读取stdin。
+// 与GNU grep一样没有匹配时也返回错误，命令行模式下以非0状态退出
+func runGrep(pattern string, paths []string, options *GrepOptions, stdin io.Reader, w io.Writer, processQuery func(string, bool)) error {
+	if options.FixedStrings {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if options.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf(T("无效的正则表达式: %v"), err)
+	}
+	color, err := colorEnabled(options.Color, w)
+	if err != nil {
+		return err
+	}
+	// 不在管道中时读取终端输入
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	g := &grepper{options: options, re: re, stdin: stdin, color: color, explicit: true}
+
+	if len(paths) == 0 && options.Recursive {
+		paths = []string{"."}
+	}
+	if len(paths) == 0 {
+		g.search(stdin, "(standard input)")
+	} else {
+		g.names = len(paths) > 1 || options.Recursive
+		for _, path := range paths {
+			g.searchPath(path)
+		}
+	}
+	fmt.Fprint(w, g.out.String())
+
+	if (options.AISummarize || options.Ask != "") && g.matched {
+		vars := map[string]string{"content": stripANSI(g.out.String())}
+		if options.Ask != "" {
+			vars["question"] = options.Ask
+		}
+		summaryPrompt, err := renderPrompt("search-summary", vars)
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		fmt.Fprintln(w, T("AI总结:"))
+		processQuery(summaryPrompt, true)
+	}
+	if g.failed || !g.matched {
+		return errReported
+	}
+	return nil
+}
+
+func (g *grepper) fail(err error) {
+	fmt.Printf("grep: %v\n", err)
+	g.failed = true
+}
+
+// searchPath 搜索一个文件，-r 时递归搜索目录
+func (g *grepper) searchPath(path string) {
+	if path == "-" {
+		g.search(g.stdin, "(standard input)")
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		g.fail(err)
+		return
+	}
+	if !info.IsDir() {
+		g.explicit = true
+		g.searchFile(path)
+		return
+	}
+	if !g.options.Recursive {
+		fmt.Printf(T("grep: %s: 是目录\n"), path)
+		return
+	}
+
+	g.explicit = false
+	walk := filepath.WalkDir
+	if !g.options.NoIgnore {
+		walk = walkGitignored
+	}
+	walk(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			g.fail(err)
+			return nil
+		}
+		if p != path && globMatch(g.options.Exclude, d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(g.options.Include) > 0 && !globMatch(g.options.Include, d.Name()) {
+			return nil
+		}
+		g.searchFile(p)
+		return nil
+	})
+}
+
+// globMatch 判断name是否匹配任意一个通配符
+func globMatch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *grepper) searchFile(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		g.fail(err)
+		return
+	}
+	defer f.Close()
+	g.search(f, path)
+}
+
+// search 逐行搜索r，按GNU grep的格式输出匹配行和上下文，不同的上下文分组之间用--分隔
+func (g *grepper) search(r io.Reader, name string) {
+	reader := bufio.NewReader(r)
+	// 开头有NUL字节的当作二进制文件跳过
+	if head, _ := reader.Peek(8000); bytes.IndexByte(head, 0) >= 0 {
+		if g.explicit {
+			fmt.Printf(T("grep: %s: 二进制文件，已跳过\n"), name)
+		}
+		return
+	}
+
+	type line struct {
+		num  int
+		text string
+	}
+	var before []line
+	after, last := 0, 0 // last是最后输出的行号
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for num := 1; scanner.Scan(); num++ {
+		text := scanner.Text()
+		if g.re.MatchString(text) != g.options.InvertMatch {
+			g.matched = true
+			if g.options.FilesWithMatches {
+				g.out.WriteString(g.paint(grepColorFile, name) + "\n")
+				return
+			}
+			first := num - len(before)
+			if g.options.Context > 0 && g.printed && (last == 0 || first > last+1) {
+				g.out.WriteString(g.paint(grepColorSep, "--") + "\n")
+			}
+			for _, l := range before {
+				g.writeLine(name, l.num, '-', l.text, false)
+			}
+			before = before[:0]
+			g.writeLine(name, num, ':', text, true)
+			after, last = g.options.Context, num
+			g.printed = true
+		} else if after > 0 {
+			g.writeLine(name, num, '-', text, false)
+			after, last = after-1, num
+		} else if g.options.Context > 0 {
+			before = append(before, line{num, text})
+			if len(before) > g.options.Context {
+				before = before[1:]
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		g.fail(fmt.Errorf("%s: %v", name, err))
+	}
+}
+
+// writeLine 输出一行，sep为:表示匹配行，-表示上下文
+func (g *grepper) writeLine(name string, num int, sep byte, text string, match bool) {
+	if g.names {
+		g.out.WriteString(g.paint(grepColorFile, name) + g.paint(grepColorSep, string(sep)))
+	}
+	if g.options.LineNumber {
+		g.out.WriteString(g.paint(grepColorLine, strconv.Itoa(num)) + g.paint(grepColorSep, string(sep)))
+	}
+	if match && g.color && !g.options.InvertMatch {
+		text = g.re.ReplaceAllStringFunc(text, func(s string) string { return g.paint(grepColorMatch, s) })
+	}
+	g.out.WriteString(text + "\n")
+}
+
+func (g *grepper) paint(color, s string) string {
+	if !g.color || s == "" {
+		return s
+	}
+	return color + s + ansiReset
+}
+
+func newGrepCmd() *cobra.Command {
+	options := newGrepOptions()
+	cmd := &cobra.Command{
+		Use:   "grep 模式 [文件]...",
+		Short: "在文件中搜索，--ai 让AI总结匹配结果",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGrep(args[0], args[1:], options, os.Stdin, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	cmd.Flags().Lookup("color").NoOptDefVal = "always"
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newGrepCmd())
+	registerCommand(&Command{
+		Name:     "grep",
+		Usage:    "[-rinvlF] [-C N] [--ai] 模式 [文件...]",
+		Help:     "在文件中搜索，--ai 让AI总结匹配结果",
+		Flags:    optionFlags(GrepOptions{}),
+		Record:   true,
+		Complete: completeFiles,
+		Operands: func(args []string) ([]string, error) {
+			_, _, paths, err := parseGrepArgs(args)
+			return paths, err
+		},
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleGrep(input, stdin, stdout, r.Query(stdout))
+		},
+	})
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGrepArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		options GrepOptions
+		pattern string
+		paths   []string
+	}{
+		{[]string{"error", "app.log"}, GrepOptions{Color: "auto"}, "error", []string{"app.log"}},
+		{[]string{"-rn", "TODO"}, GrepOptions{Recursive: true, LineNumber: true, Color: "auto"}, "TODO", []string{}},
+		{[]string{"-C2", "x", "a", "b"}, GrepOptions{Context: 2, Color: "auto"}, "x", []string{"a", "b"}},
+		{[]string{"-iC", "3", "x"}, GrepOptions{IgnoreCase: true, Context: 3, Color: "auto"}, "x", []string{}},
+		{[]string{"x", ".", "-r", "--context=1"}, GrepOptions{Recursive: true, Context: 1, Color: "auto"}, "x", []string{"."}},
+		{[]string{"--include", "*.go", "--include=*.md", "--exclude", "vendor", "x"},
+			GrepOptions{Include: []string{"*.go", "*.md"}, Exclude: []string{"vendor"}, Color: "auto"}, "x", []string{}},
+		{[]string{"--color", "x"}, GrepOptions{Color: "always"}, "x", []string{}},
+		{[]string{"--color=never", "-Fvl", "x"}, GrepOptions{Color: "never", FixedStrings: true, InvertMatch: true, FilesWithMatches: true}, "x", []string{}},
+		{[]string{"--no-ignore", "--ask", "why?", "x"}, GrepOptions{NoIgnore: true, Ask: "why?", Color: "auto"}, "x", []string{}},
+		{[]string{"-n", "--", "-v", "-r"}, GrepOptions{LineNumber: true, Color: "auto"}, "-v", []string{"-r"}},
+	}
+	for _, tt := range tests {
+		options, pattern, paths, err := parseGrepArgs(tt.args)
+		if err != nil {
+			t.Errorf("parseGrepArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(*options, tt.options) || pattern != tt.pattern || !reflect.DeepEqual(paths, tt.paths) {
+			t.Errorf("parseGrepArgs(%q) = %+v, %q, %q, want %+v, %q, %q",
+				tt.args, *options, pattern, paths, tt.options, tt.pattern, tt.paths)
+		}
+	}
+
+	for _, args := range [][]string{
+		nil,
+		{"-n"},
+		{"-x", "a"},
+		{"--unknown", "a"},
+		{"-C", "-1", "a"},
+		{"a", "--include"},
+		{"a", "-C"},
+	} {
+		if _, _, _, err := parseGrepArgs(args); err == nil {
+			t.Errorf("parseGrepArgs(%q) accepted invalid arguments", args)
+		}
+	}
+}
+
+func TestRunGrepIncludeExclude(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"main.go", "notes.md", "vendor/lib.go", "src/app.go", "src/app_test.go", "build/out.go"} {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte("needle\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(".gitignore", []byte("build/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-rl", "needle"}, []string{"main.go", "notes.md", "src/app.go", "src/app_test.go", "vendor/lib.go"}},
+		{[]string{"-rl", "--no-ignore", "needle"}, []string{"build/out.go", "main.go", "notes.md", "src/app.go", "src/app_test.go", "vendor/lib.go"}},
+		{[]string{"-rl", "--include", "*.go", "needle"}, []string{"main.go", "src/app.go", "src/app_test.go", "vendor/lib.go"}},
+		{[]string{"-rl", "--include", "*.go", "--include", "*.md", "--exclude", "*_test.go", "needle"}, []string{"main.go", "notes.md", "src/app.go", "vendor/lib.go"}},
+		// 排除目录时跳过整个目录
+		{[]string{"-rl", "--exclude", "vendor", "--exclude", "src", "needle"}, []string{"main.go", "notes.md"}},
+		// --include 只作用于递归时遇到的文件，直接指定的文件总会被搜索
+		{[]string{"-l", "--include", "*.go", "needle", "notes.md"}, []string{"notes.md"}},
+		{[]string{"-rl", "--include", "*.go", "needle", "src"}, []string{"src/app.go", "src/app_test.go"}},
+	}
+	for _, tt := range tests {
+		options, pattern, paths, err := parseGrepArgs(append(tt.args, "--color=never"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		if err := runGrep(pattern, paths, options, nil, &b, nil); err != nil {
+			t.Errorf("grep %q: %v", tt.args, err)
+		}
+		got := strings.Fields(b.String())
+		for i := range got {
+			got[i] = strings.TrimPrefix(got[i], "./")
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("grep %q = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	var b strings.Builder
+	options, _, _, _ := parseGrepArgs([]string{"-r", "x", "--color=never"})
+	if err := runGrep("missing", nil, options, nil, &b, nil); err != errReported {
+		t.Errorf("grep without matches: err = %v, want errReported", err)
+	}
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// secretPatterns 匹配到的输入不写入历史，避免密钥明文落盘
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(api[_-]?key|access[_-]?key|secret|token|passw(or)?d|pwd)\s*[=:]\s*\S+`),
+	regexp.MustCompile(`(?i)authorization:\s*\S+`),
+	regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/-]{8,}`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+}
+
+// History REPL的持久化输入历史，保存在 ~/.ai-cli/history
+type History struct {
+	Path   string
+	Size   int  // 最多保留的条数
+	Dedup  bool // 为true时再次输入相同内容会移除之前的记录
+	Lines  []string
+	ignore []*regexp.Regexp
+}
+
+func historyPath() string {
+	if path := viper.GetString("history.file"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".ai-cli-history"
+	}
+	return filepath.Join(home, ".ai-cli", "history")
+}
+
+// LoadHistory 按配置读取历史文件，文件不存在时返回空历史
+func LoadHistory() *History {
+	h := &History{
+		Path:   historyPath(),
+		Size:   1000,
+		Dedup:  true,
+		ignore: secretPatterns,
+	}
+	// 使用绝对路径，REPL中cd之后仍然写入同一个文件
+	if abs, err := filepath.Abs(h.Path); err == nil {
+		h.Path = abs
+	}
+	if viper.IsSet("history.size") {
+		h.Size = viper.GetInt("history.size")
+	}
+	if viper.IsSet("history.dedup") {
+		h.Dedup = viper.GetBool("history.dedup")
+	}
+	for _, pattern := range viper.GetStringSlice("history.ignorePatterns") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fmt.Printf(T("忽略无效的history.ignorePatterns %q: %v\n"), pattern, err)
+			continue
+		}
+		h.ignore = append(h.ignore, re)
+	}
+
+	f, err := os.Open(h.Path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+	h.Lines = readHistory(f)
+	h.trim()
+	return h
+}
+
+// readHistory 读取历史文件，每行是一条用Go语法加引号的记录。
+// 不以引号开头的行是旧格式，多行输入的每行以反斜杠结尾，读取时再拼回一条
+func readHistory(r io.Reader) []string {
+	var lines, pending []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(pending) == 0 && strings.HasPrefix(line, `"`) {
+			if s, err := strconv.Unquote(line); err == nil {
+				if s != "" {
+					lines = append(lines, s)
+				}
+				continue
+			}
+		}
+		if strings.HasSuffix(line, "\\") {
+			pending = append(pending, strings.TrimSuffix(line, "\\"))
+			continue
+		}
+		if line = strings.Join(append(pending, line), "\n"); line != "" {
+			lines = append(lines, line)
+		}
+		pending = nil
+	}
+	return lines
+}
+
+// Ignored 判断一行是否不应记录：以空格开头或包含密钥
+func (h *History) Ignored(line string) bool {
+	if strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") {
+		return true
+	}
+	for _, re := range h.ignore {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add 记录一行输入并写入文件，返回是否被记录
+func (h *History) Add(line string) bool {
+	if h.Size <= 0 || h.Ignored(line) {
+		return false
+	}
+	if n := len(h.Lines); n > 0 && h.Lines[n-1] == line {
+		return false
+	}
+
+	rewrite := false
+	if h.Dedup {
+		kept := h.Lines[:0]
+		for _, l := range h.Lines {
+			if l != line {
+				kept = append(kept, l)
+			}
+		}
+		rewrite = len(kept) != len(h.Lines)
+		h.Lines = kept
+	}
+	h.Lines = append(h.Lines, line)
+	if h.trim() {
+		rewrite = true
+	}
+
+	if rewrite {
+		h.save()
+	} else {
+		h.append(line)
+	}
+	return true
+}
+
+// trim 只保留最近Size条
+func (h *History) trim() bool {
+	if h.Size >= 0 && len(h.Lines) > h.Size {
+		h.Lines = h.Lines[len(h.Lines)-h.Size:]
+		return true
+	}
+	return false
+}
+
+func (h *History) append(line string) {
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0755); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(encodeHistoryLine(line) + "\n")
+}
+
+// save 重写整个历史文件
+func (h *History) save() error {
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0755); err != nil {
+		return err
+	}
+	var data strings.Builder
+	for _, line := range h.Lines {
+		data.WriteString(encodeHistoryLine(line) + "\n")
+	}
+	tmp := h.Path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(data.String()), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.Path)
+}
+
+// encodeHistoryLine 把一条记录编码为一行，换行和反斜杠都被转义，
+// 以反斜杠结尾的输入(如 cd C:\)不会和下一条混在一起
+func encodeHistoryLine(line string) string {
+	return strconv.Quote(line)
+}
+
+// Clear 清空历史记录
+func (h *History) Clear() error {
+	h.Lines = nil
+	return h.save()
+}
+
+// Expand 展开 !!、!N、!-N 形式的历史引用，不是历史引用时原样返回
+func (h *History) Expand(input string) (string, bool, error) {
+	if !strings.HasPrefix(input, "!") {
+		return input, false, nil
+	}
+	ref := input[1:]
+	index := 0
+	if ref == "!" {
+		index = len(h.Lines)
+	} else {
+		n, err := strconv.Atoi(ref)
+		if err != nil || n == 0 {
+			return input, false, nil
+		}
+		index = n
+		if n < 0 {
+			index = len(h.Lines) + n + 1
+		}
+	}
+	if index < 1 || index > len(h.Lines) {
+		return "", true, fmt.Errorf(T("%s: 历史记录中没有这一条"), input)
+	}
+	return h.Lines[index-1], true, nil
+}
+
+// HandleHistory 处理REPL中的 /history [N] 和 /history clear
+func HandleHistory(input string, history *History, w io.Writer) {
+	arg := strings.TrimSpace(strings.TrimPrefix(input, "/history"))
+	if arg == "clear" {
+		if err := history.Clear(); err != nil {
+			fmt.Printf(T("清空历史失败: %v\n"), err)
+			return
+		}
+		fmt.Println(T("历史记录已清空"))
+		return
+	}
+
+	count := 20
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			fmt.Println(T("用法: /history [N] | /history clear"))
+			return
+		}
+		count = n
+	}
+	start := len(history.Lines) - count
+	if start < 0 {
+		start = 0
+	}
+	width := len(strconv.Itoa(len(history.Lines)))
+	for i := start; i < len(history.Lines); i++ {
+		fmt.Fprintf(w, "%*d  %s\n", width, i+1, strings.ReplaceAll(history.Lines[i], "\n", "\n"+strings.Repeat(" ", width+2)))
+	}
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:     "/history",
+		Usage:    "[N|clear]",
+		Help:     "显示最近的输入历史，!N 重新执行第N条",
+		Complete: completeWords("clear"),
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleHistory(input, r.History, stdout)
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	entries := []string{
+		"ls -l",
+		`cd C:\`,
+		`!make \`,
+		"line one\nline two",
+		"ends with newline\n",
+		`"quoted question"`,
+		`back\slash and \n literal`,
+		"中文问题 \t tab",
+	}
+	h := &History{Path: filepath.Join(t.TempDir(), "history"), Size: 100}
+	for _, e := range entries {
+		h.Add(e)
+	}
+	h.save()
+	// 逐条追加和整体重写使用同一种编码
+	h.append("appended")
+
+	f, err := os.Open(h.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	want := append(entries, "appended")
+	if got := readHistory(f); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %q, want %q", got, want)
+	}
+}
+
+func TestReadHistoryLegacy(t *testing.T) {
+	data := "ls -l\nfirst\\\nsecond\n\n" + `"new"` + "\nplain\n"
+	got := readHistory(strings.NewReader(data))
+	want := []string{"ls -l", "first\nsecond", "new", "plain"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readHistory = %q, want %q", got, want)
+	}
+}
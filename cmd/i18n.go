@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// defaultLanguage 没有配置语言且环境变量中没有可用的语言时使用
+const defaultLanguage = "zh-CN"
+
+// language 当前界面语言，zh-CN 或 en
+var language = defaultLanguage
+
+// catalogs 各语言的界面文字。代码中直接写中文原文，键是去掉末尾换行的中文原文，
+// 没有翻译的文字原样显示
+var catalogs = map[string]map[string]string{
+	"en": enMessages,
+}
+
+// T 把中文原文翻译为当前语言。原文以换行结尾时，翻译也保留换行
+func T(s string) string {
+	catalog := catalogs[language]
+	if catalog == nil {
+		return s
+	}
+	if t, ok := catalog[s]; ok {
+		return t
+	}
+	if key, ok := strings.CutSuffix(s, "\n"); ok {
+		if t, ok := catalog[key]; ok {
+			return t + "\n"
+		}
+	}
+	return s
+}
+
+// languageName 返回当前语言的名称，用于要求AI使用同样的语言回答
+func languageName() string {
+	if language == "en" {
+		return "English"
+	}
+	return "中文"
+}
+
+// parseLanguage 把 zh_CN.UTF-8、en-US 等写法归一为支持的语言。
+// C、POSIX 和空值表示没有指定，返回空字符串
+func parseLanguage(s string) string {
+	s = strings.ToLower(s)
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	switch {
+	case s == "" || s == "c" || s == "posix":
+		return ""
+	case strings.HasPrefix(s, "zh"):
+		return "zh-CN"
+	default:
+		return "en"
+	}
+}
+
+// detectLanguage 依次使用 --lang、ui.language、LC_ALL、LC_MESSAGES 和 LANG
+func detectLanguage() string {
+	if lang := parseLanguage(viper.GetString("ui.language")); lang != "" {
+		return lang
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := parseLanguage(os.Getenv(env)); lang != "" {
+			return lang
+		}
+	}
+	return defaultLanguage
+}
+
+var languageApplied bool
+
+// applyLanguage 确定界面语言并翻译命令行的帮助文字，只执行一次
+func applyLanguage() {
+	if languageApplied {
+		return
+	}
+	languageApplied = true
+	language = detectLanguage()
+	translateCommand(rootCmd)
+}
+
+// translateCommand 翻译命令及其子命令的用法、说明和选项说明
+func translateCommand(c *cobra.Command) {
+	c.Use = T(c.Use)
+	c.Short = T(c.Short)
+	c.Long = T(c.Long)
+	translate := func(f *pflag.Flag) { f.Usage = T(f.Usage) }
+	c.Flags().VisitAll(translate)
+	c.PersistentFlags().VisitAll(translate)
+	for _, sub := range c.Commands() {
+		translateCommand(sub)
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().String("lang", "", "界面语言: zh-CN|en")
+	viper.BindPFlag("ui.language", rootCmd.PersistentFlags().Lookup("lang"))
+	rootCmd.RegisterFlagCompletionFunc("lang", cobra.FixedCompletions([]string{"zh-CN", "en"}, cobra.ShellCompDirectiveNoFileComp))
+
+	cobra.OnInitialize(applyLanguage)
+	// 显示帮助时不会执行OnInitialize，需要先翻译
+	help := rootCmd.HelpFunc()
+	rootCmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		applyLanguage()
+		help(c, args)
+	})
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jsonSchema 校验JSON Schema的常用子集：type、enum、const、数值和长度范围、pattern、format、
+// properties/required/additionalProperties、items、allOf/anyOf/oneOf/not，以及本文档内的$ref
+type jsonSchema struct {
+	root map[string]any
+	// refs 正在校验的$ref和实例路径，同一路径上再次遇到同一个$ref说明引用成环
+	refs map[string]bool
+}
+
+func parseJSONSchema(data []byte) (*jsonSchema, error) {
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf(T("schema不是合法的JSON对象: %v"), err)
+	}
+	return &jsonSchema{root: root}, nil
+}
+
+// Validate 校验JSON文档，返回所有不符合schema的地方
+func (s *jsonSchema) Validate(data []byte) []string {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return []string{fmt.Sprintf(T("不是合法的JSON: %v"), err)}
+	}
+	var errs []string
+	s.validate(s.root, doc, "", &errs)
+	return errs
+}
+
+// resolve 解析"#/..."形式的$ref
+func (s *jsonSchema) resolve(ref string) (map[string]any, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	var node any = s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		node = m[part]
+	}
+	m, ok := node.(map[string]any)
+	return m, ok
+}
+
+func jsonType(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == math.Trunc(x) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+func typeMatches(want, got string) bool {
+	return want == got || (want == "number" && got == "integer")
+}
+
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+var formatPatterns = map[string]*regexp.Regexp{
+	"email": regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`),
+	"uri":   regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:\S+$`),
+	"uuid":  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`),
+}
+
+func checkFormat(format, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05", strings.TrimSuffix(value, "Z"))
+		return err == nil
+	}
+	if re, ok := formatPatterns[format]; ok {
+		return re.MatchString(value)
+	}
+	return true
+}
+
+func (s *jsonSchema) validate(schema map[string]any, v any, path string, errs *[]string) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, fmt.Sprintf("%s: %s", pointer(path), fmt.Sprintf(T(format), args...)))
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, found := s.resolve(ref)
+		if !found {
+			fail("无法解析 $ref %s", ref)
+			return
+		}
+		key := ref + "\x00" + path
+		if s.refs[key] {
+			fail("$ref %s 循环引用", ref)
+			return
+		}
+		if s.refs == nil {
+			s.refs = map[string]bool{}
+		}
+		s.refs[key] = true
+		s.validate(target, v, path, errs)
+		delete(s.refs, key)
+		return
+	}
+
+	got := jsonType(v)
+	switch t := schema["type"].(type) {
+	case string:
+		if !typeMatches(t, got) {
+			fail("类型应为 %s，实际为 %s", t, got)
+			return
+		}
+	case []any:
+		matched := false
+		var names []string
+		for _, item := range t {
+			name, _ := item.(string)
+			names = append(names, name)
+			if typeMatches(name, got) {
+				matched = true
+			}
+		}
+		if !matched {
+			fail("类型应为 %s 之一，实际为 %s", strings.Join(names, "/"), got)
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			data, _ := json.Marshal(enum)
+			fail("应为以下值之一 %s", data)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, v) {
+		data, _ := json.Marshal(c)
+		fail("应等于 %s", data)
+	}
+
+	switch x := v.(type) {
+	case float64:
+		if min, ok := schema["minimum"].(float64); ok && x < min {
+			fail("不能小于 %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && x > max {
+			fail("不能大于 %v", max)
+		}
+		if min, ok := schema["exclusiveMinimum"].(float64); ok && x <= min {
+			fail("必须大于 %v", min)
+		}
+		if max, ok := schema["exclusiveMaximum"].(float64); ok && x >= max {
+			fail("必须小于 %v", max)
+		}
+	case string:
+		length := len([]rune(x))
+		if min, ok := schema["minLength"].(float64); ok && length < int(min) {
+			fail("长度不能小于 %v", min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && length > int(max) {
+			fail("长度不能大于 %v", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(x) {
+				fail("不匹配 pattern %s", pattern)
+			}
+		}
+		if format, ok := schema["format"].(string); ok && !checkFormat(format, x) {
+			fail("不符合 format %s", format)
+		}
+	case []any:
+		if min, ok := schema["minItems"].(float64); ok && len(x) < int(min) {
+			fail("元素个数不能少于 %v", min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && len(x) > int(max) {
+			fail("元素个数不能多于 %v", max)
+		}
+		if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+			for i := range x {
+				for j := i + 1; j < len(x); j++ {
+					if reflect.DeepEqual(x[i], x[j]) {
+						fail("第 %d 和第 %d 个元素重复", i, j)
+					}
+				}
+			}
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range x {
+				s.validate(items, item, fmt.Sprintf("%s/%d", path, i), errs)
+			}
+		}
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, exists := x[name]; !exists {
+					fail("缺少必填字段 %s", name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := path + "/" + k
+			if prop, ok := properties[k].(map[string]any); ok {
+				s.validate(prop, x[k], childPath, errs)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					fail("不允许的字段 %s", k)
+				}
+			case map[string]any:
+				s.validate(additional, x[k], childPath, errs)
+			}
+		}
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			if m, ok := sub.(map[string]any); ok {
+				s.validate(m, v, path, errs)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok && s.countMatches(anyOf, v, path) == 0 {
+		fail("不满足 anyOf 中的任何一个schema")
+	}
+	if one, ok := schema["oneOf"].([]any); ok {
+		if n := s.countMatches(one, v, path); n != 1 {
+			fail("应恰好满足 oneOf 中的一个schema，实际满足 %d 个", n)
+		}
+	}
+	if not, ok := schema["not"].(map[string]any); ok && s.countMatches([]any{not}, v, path) == 1 {
+		fail("不应满足 not 中的schema")
+	}
+}
+
+func (s *jsonSchema) countMatches(schemas []any, v any, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		m, ok := sub.(map[string]any)
+		if !ok {
+			continue
+		}
+		var errs []string
+		s.validate(m, v, path, &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
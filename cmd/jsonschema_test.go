@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONSchemaResolve(t *testing.T) {
+	s, err := parseJSONSchema([]byte(`{"$defs":{"a/b":{"type":"string"},"c~d":{"type":"integer"}},"items":[{"type":"null"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ref   string
+		typ   string
+		found bool
+	}{
+		{"#", "", true},
+		{"#/$defs/a~1b", "string", true},
+		{"#/$defs/c~0d", "integer", true},
+		{"#/$defs/missing", "", false},
+		{"#/items/0", "", false},
+		{"other.json#/$defs/a", "", false},
+	}
+	for _, tt := range tests {
+		target, found := s.resolve(tt.ref)
+		if found != tt.found {
+			t.Errorf("resolve(%q) found = %v, want %v", tt.ref, found, tt.found)
+			continue
+		}
+		if typ, _ := target["type"].(string); found && typ != tt.typ {
+			t.Errorf("resolve(%q) type = %q, want %q", tt.ref, typ, tt.typ)
+		}
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		errs   []string // 每个错误应包含的内容，为空表示校验通过
+	}{
+		{"type", `{"type":"string"}`, `"x"`, nil},
+		{"type mismatch", `{"type":"string"}`, `1`, []string{"/: "}},
+		{"required", `{"type":"object","required":["id"]}`, `{}`, []string{"id"}},
+		{"nested ref", `{"$defs":{"id":{"type":"integer"}},"properties":{"id":{"$ref":"#/$defs/id"}}}`, `{"id":"x"}`, []string{"/id: "}},
+		{"recursive schema", `{"type":"object","properties":{"child":{"$ref":"#"}}}`, `{"child":{"child":{}}}`, nil},
+		{"recursive schema error", `{"type":"object","properties":{"child":{"$ref":"#"}}}`, `{"child":{"child":1}}`, []string{"/child/child: "}},
+		{"self ref", `{"$ref":"#"}`, `{}`, []string{"$ref #"}},
+		{"ref cycle", `{"$defs":{"a":{"$ref":"#/$defs/b"},"b":{"$ref":"#/$defs/a"}},"$ref":"#/$defs/a"}`, `1`, []string{"$ref #/$defs/a"}},
+		{"allOf cycle", `{"$defs":{"a":{"allOf":[{"$ref":"#/$defs/a"}]}},"properties":{"x":{"$ref":"#/$defs/a"}}}`, `{"x":1}`, []string{"/x: "}},
+		{"unresolved ref", `{"$ref":"#/$defs/missing"}`, `1`, []string{"#/$defs/missing"}},
+	}
+	for _, tt := range tests {
+		s, err := parseJSONSchema([]byte(tt.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		errs := s.Validate([]byte(tt.doc))
+		if len(errs) != len(tt.errs) {
+			t.Errorf("%s: Validate(%s) = %q, want %d errors", tt.name, tt.doc, errs, len(tt.errs))
+			continue
+		}
+		for i, want := range tt.errs {
+			if !strings.Contains(errs[i], want) {
+				t.Errorf("%s: error %q does not contain %q", tt.name, errs[i], want)
+			}
+		}
+	}
+}
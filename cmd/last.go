@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// commandOutput 内置命令或shell命令的一次输出
+type commandOutput struct {
+	Command string
+	Output  string
+}
+
+// rememberOutput 保存命令输出，只保留最近 history.outputs 条
+func (r *REPL) rememberOutput(command, output string) {
+	size := 10
+	if viper.IsSet("history.outputs") {
+		size = viper.GetInt("history.outputs")
+	}
+	if size <= 0 || strings.TrimSpace(output) == "" {
+		return
+	}
+	r.outputs = append(r.outputs, commandOutput{Command: command, Output: stripANSI(output)})
+	if len(r.outputs) > size {
+		r.outputs = r.outputs[len(r.outputs)-size:]
+	}
+}
+
+// lastOutput 返回倒数第n条命令输出，n从1开始
+func (r *REPL) lastOutput(n int) (*commandOutput, error) {
+	if len(r.outputs) == 0 {
+		return nil, errors.New(T("还没有命令输出"))
+	}
+	if n < 1 || n > len(r.outputs) {
+		return nil, fmt.Errorf(T("只保存了最近 %d 条命令输出"), len(r.outputs))
+	}
+	return &r.outputs[len(r.outputs)-n], nil
+}
+
+// fenced 把命令输出放进代码块
+func (o *commandOutput) fenced() string {
+	fence := codeFence(o.Output)
+	return fmt.Sprintf(T("命令 `%s` 的输出:\n%s\n%s\n%s"), o.Command, fence, strings.TrimRight(o.Output, "\n"), fence)
+}
+
+var lastRefPattern = regexp.MustCompile(`\$_(\d*)`)
+
+// expandLastRefs 把问题中的 $_ 和 $_N 替换为最近第N条命令的输出。
+// 还没有命令输出时原样返回，避免误伤Perl等代码中的$_
+func (r *REPL) expandLastRefs(question string) (string, error) {
+	if len(r.outputs) == 0 || !strings.Contains(question, "$_") {
+		return question, nil
+	}
+	var err error
+	expanded := lastRefPattern.ReplaceAllStringFunc(question, func(ref string) string {
+		n := 1
+		if len(ref) > 2 {
+			n, _ = strconv.Atoi(ref[2:])
+		}
+		o, e := r.lastOutput(n)
+		if e != nil {
+			err = fmt.Errorf("%s: %v", ref, e)
+			return ref
+		}
+		return "\n" + o.fenced() + "\n"
+	})
+	return expanded, err
+}
+
+// HandleLast 处理 /last [N]：不带参数时列出保存的命令输出，带N时输出倒数第N条
+func (r *REPL) HandleLast(input string, w io.Writer) {
+	args, err := builtinArgs(input) // Skip "/last"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if len(args) == 0 {
+		if len(r.outputs) == 0 {
+			fmt.Println(T("还没有命令输出"))
+			return
+		}
+		for n := 1; n <= len(r.outputs); n++ {
+			o := r.outputs[len(r.outputs)-n]
+			fmt.Fprintf(w, T("%3d  %s  (%d 行)\n"), n, o.Command, strings.Count(strings.TrimRight(o.Output, "\n"), "\n")+1)
+		}
+		return
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || len(args) > 1 {
+		fmt.Println(T("用法: /last [N]"))
+		return
+	}
+	o, err := r.lastOutput(n)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	io.WriteString(w, o.Output)
+}
+
+// HandleAsk 处理 /ask 问题：把最近一条命令输出作为上下文提问
+func (r *REPL) HandleAsk(input string, w io.Writer) {
+	question := strings.TrimSpace(strings.TrimPrefix(input, "/ask"))
+	if question == "" {
+		fmt.Println(T("用法: /ask 问题"))
+		return
+	}
+	o, err := r.lastOutput(1)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	r.Query(w)(question+"\n\n"+o.fenced(), false)
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:  "/last",
+		Usage: "[N]",
+		Help:  "列出最近的命令输出，/last N 显示倒数第N条，问题中可用 $_、$_N 引用",
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			r.HandleLast(input, stdout)
+		},
+	})
+	registerCommand(&Command{
+		Name:  "/ask",
+		Usage: "问题",
+		Help:  "把最近一条命令的输出作为上下文提问",
+		Run:   func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { r.HandleAsk(input, stdout) },
+	})
+}
@@ -0,0 +1,821 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted 用户在输入时按下了Ctrl+C
+var errInterrupted = errors.New("interrupted")
+
+// 特殊按键，用负数和普通字符区分
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyAltD
+	keyAltBackspace
+	keyEsc
+	keyPaste
+	keyUnknown
+)
+
+// 控制字符
+const (
+	ctrlA     = 1
+	ctrlB     = 2
+	ctrlC     = 3
+	ctrlD     = 4
+	ctrlE     = 5
+	ctrlF     = 6
+	ctrlG     = 7
+	ctrlH     = 8
+	tab       = 9
+	ctrlJ     = 10
+	ctrlK     = 11
+	ctrlL     = 12
+	enter     = 13
+	ctrlN     = 14
+	ctrlP     = 16
+	ctrlR     = 18
+	ctrlT     = 20
+	ctrlU     = 21
+	ctrlW     = 23
+	ctrlY     = 25
+	backspace = 127
+)
+
+// LineEditor REPL使用的行编辑器，终端下以原始模式读取按键，
+// 支持emacs和vi两套键位、历史记录、Ctrl+R反向搜索和Tab补全。
+// 标准输入不是终端时退化为按行读取
+type LineEditor struct {
+	// Complete 返回光标前需要替换的起始位置(字节偏移)和候选项
+	Complete func(line string, pos int) (start int, candidates []string)
+	ViMode   bool
+
+	in      *os.File
+	out     io.Writer
+	reader  *bufio.Reader
+	history []string
+
+	prompt    string
+	buf       []rune
+	pos       int
+	cursorRow int    // 光标相对提示符首行的行数，用于重绘
+	killed    []rune // Ctrl+K/U/W等删除的内容，Ctrl+Y粘贴
+	histIndex int
+	saved     []rune // 浏览历史之前正在编辑的内容
+	lastTab   bool
+	viNormal  bool
+	viPending rune   // vi普通模式下等待动作的操作符(d/c/r)
+	pasted    string // 括号粘贴模式下粘贴的内容
+}
+
+func NewLineEditor() *LineEditor {
+	return &LineEditor{
+		in:     os.Stdin,
+		out:    os.Stdout,
+		reader: bufio.NewReader(os.Stdin),
+	}
+}
+
+// SetHistory 设置上下键和Ctrl+R使用的历史记录，最早的在前
+func (e *LineEditor) SetHistory(lines []string) {
+	e.history = lines
+}
+
+// History 返回所有历史记录，最早的在前
+func (e *LineEditor) History() []string {
+	return e.history
+}
+
+// ReadLine 显示提示符并读取一行。Ctrl+C返回errInterrupted，空行上按Ctrl+D或输入结束返回io.EOF
+func (e *LineEditor) ReadLine(prompt string) (string, error) {
+	if !isTerminal(e.in) || !isTerminal(os.Stdout) {
+		return e.readLinePlain(prompt)
+	}
+	state, err := makeRaw(e.in)
+	if err != nil {
+		return e.readLinePlain(prompt)
+	}
+	defer restoreTerminal(e.in, state)
+	// 开启括号粘贴模式，粘贴的多行内容作为整体插入而不是逐行提交
+	fmt.Fprint(e.out, "\033[?2004h")
+	defer fmt.Fprint(e.out, "\033[?2004l")
+	return e.edit(prompt)
+}
+
+// edit 从e.reader逐个读取按键编辑一行，直到回车、Ctrl+C或输入结束。终端需要已处于原始模式
+func (e *LineEditor) edit(prompt string) (string, error) {
+	e.prompt = prompt
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.cursorRow = 0
+	e.histIndex = len(e.history)
+	e.saved = nil
+	e.lastTab = false
+	e.viNormal = false
+	e.viPending = 0
+	e.refresh()
+
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			return "", err
+		}
+		if key == ctrlR {
+			key, err = e.reverseSearch()
+			if err != nil {
+				return "", err
+			}
+			if key == 0 {
+				continue
+			}
+		}
+
+		if key != tab {
+			e.lastTab = false
+		}
+		line, done, err := e.handleKey(key)
+		if done || err != nil {
+			return line, err
+		}
+	}
+}
+
+// ReadMultiline 读取一条可能跨多行的输入：以"""开始的块读到下一个"""为止，
+// 以反斜杠结尾的行与下一行连接，粘贴的多行内容本身就是一条输入
+func (e *LineEditor) ReadMultiline(prompt, continuation string) (string, error) {
+	line, err := e.ReadLine(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(line), `"""`); ok && !strings.Contains(line, "\n") {
+		if body, closed := strings.CutSuffix(rest, `"""`); closed {
+			return body, nil
+		}
+		lines := []string{}
+		if rest != "" {
+			lines = append(lines, rest)
+		}
+		for {
+			next, err := e.ReadLine(continuation)
+			if err != nil {
+				return "", err
+			}
+			if body, closed := strings.CutSuffix(strings.TrimRight(next, " \t"), `"""`); closed {
+				if body != "" {
+					lines = append(lines, body)
+				}
+				return strings.Join(lines, "\n"), nil
+			}
+			lines = append(lines, next)
+		}
+	}
+
+	for strings.HasSuffix(line, "\\") {
+		next, err := e.ReadLine(continuation)
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimSuffix(line, "\\") + "\n" + next
+	}
+	return line, nil
+}
+
+func (e *LineEditor) readLinePlain(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+	line, err := e.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readKey 读取一个按键，把转义序列转换成特殊按键
+func (e *LineEditor) readKey() (rune, error) {
+	r, _, err := e.reader.ReadRune()
+	if err != nil || r != 27 {
+		return r, err
+	}
+	// 单独按下ESC时后面不会紧跟其他字节
+	if e.reader.Buffered() == 0 {
+		return keyEsc, nil
+	}
+
+	next, _, err := e.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	switch next {
+	case '[':
+		var seq strings.Builder
+		for {
+			c, err := e.reader.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			seq.WriteByte(c)
+			if c >= 0x40 && c <= 0x7e {
+				break
+			}
+		}
+		switch seq.String() {
+		case "A":
+			return keyUp, nil
+		case "B":
+			return keyDown, nil
+		case "C":
+			return keyRight, nil
+		case "D":
+			return keyLeft, nil
+		case "H", "1~", "7~":
+			return keyHome, nil
+		case "F", "4~", "8~":
+			return keyEnd, nil
+		case "3~":
+			return keyDelete, nil
+		case "200~":
+			pasted, err := e.readPaste()
+			if err != nil {
+				return 0, err
+			}
+			e.pasted = pasted
+			return keyPaste, nil
+		case "1;5C", "1;3C":
+			return keyWordRight, nil
+		case "1;5D", "1;3D":
+			return keyWordLeft, nil
+		}
+		return keyUnknown, nil
+	case 'O':
+		c, err := e.reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case 'A':
+			return keyUp, nil
+		case 'B':
+			return keyDown, nil
+		case 'C':
+			return keyRight, nil
+		case 'D':
+			return keyLeft, nil
+		case 'H':
+			return keyHome, nil
+		case 'F':
+			return keyEnd, nil
+		}
+		return keyUnknown, nil
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case 'd':
+		return keyAltD, nil
+	case backspace, ctrlH:
+		return keyAltBackspace, nil
+	}
+	if e.ViMode {
+		// vi模式下ESC后紧跟的按键属于普通模式
+		e.reader.UnreadRune()
+		return keyEsc, nil
+	}
+	return keyUnknown, nil
+}
+
+// readPaste 读取括号粘贴的内容，直到结束标记 ESC[201~
+func (e *LineEditor) readPaste() (string, error) {
+	const end = "\033[201~"
+	var b strings.Builder
+	for !strings.HasSuffix(b.String(), end) {
+		c, err := e.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(c)
+	}
+	text := strings.TrimSuffix(b.String(), end)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.TrimRight(text, "\n"), nil
+}
+
+// handleKey 处理一个按键，done为true时返回整行
+func (e *LineEditor) handleKey(key rune) (string, bool, error) {
+	switch key {
+	case enter, ctrlJ:
+		e.pos = len(e.buf)
+		e.refresh()
+		fmt.Fprint(e.out, "\n")
+		return string(e.buf), true, nil
+	case ctrlC:
+		e.pos = len(e.buf)
+		e.refresh()
+		fmt.Fprint(e.out, "^C\n")
+		return "", true, errInterrupted
+	case ctrlD:
+		if len(e.buf) == 0 {
+			fmt.Fprint(e.out, "\n")
+			return "", true, io.EOF
+		}
+		e.deleteRange(e.pos, e.pos+1, false)
+	case ctrlL:
+		fmt.Fprint(e.out, "\033[H\033[2J")
+		e.cursorRow = 0
+	case tab:
+		e.complete()
+		return "", false, nil
+	case keyUp, ctrlP:
+		e.historyMove(-1)
+	case keyDown, ctrlN:
+		e.historyMove(1)
+	case keyHome, ctrlA:
+		e.pos = 0
+	case keyEnd, ctrlE:
+		e.pos = len(e.buf)
+	case keyLeft, ctrlB:
+		if e.pos > 0 {
+			e.pos--
+		}
+	case keyRight, ctrlF:
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case keyWordLeft:
+		e.pos = e.wordLeft(e.pos)
+	case keyWordRight:
+		e.pos = e.wordRight(e.pos)
+	case keyDelete:
+		e.deleteRange(e.pos, e.pos+1, false)
+	case backspace, ctrlH:
+		if e.viNormal {
+			if e.pos > 0 {
+				e.pos--
+			}
+			break
+		}
+		e.deleteRange(e.pos-1, e.pos, false)
+	case ctrlK:
+		e.deleteRange(e.pos, len(e.buf), true)
+	case ctrlU:
+		e.deleteRange(0, e.pos, true)
+	case ctrlW, keyAltBackspace:
+		e.deleteRange(e.wordLeft(e.pos), e.pos, true)
+	case keyAltD:
+		e.deleteRange(e.pos, e.wordRight(e.pos), true)
+	case ctrlY:
+		e.insert(e.killed)
+	case keyPaste:
+		e.insert([]rune(e.pasted))
+		e.clampViCursor()
+	case ctrlT:
+		if e.pos > 0 && len(e.buf) > 1 {
+			if e.pos == len(e.buf) {
+				e.pos--
+			}
+			e.buf[e.pos-1], e.buf[e.pos] = e.buf[e.pos], e.buf[e.pos-1]
+			e.pos++
+		}
+	case keyEsc:
+		if e.ViMode && !e.viNormal {
+			e.viNormal = true
+			e.viPending = 0
+			if e.pos > 0 {
+				e.pos--
+			}
+		}
+	default:
+		if e.viNormal {
+			e.handleViKey(key)
+		} else if key >= 32 {
+			e.insert([]rune{key})
+		}
+	}
+	e.refresh()
+	return "", false, nil
+}
+
+// handleViKey 处理vi普通模式下的按键
+func (e *LineEditor) handleViKey(key rune) {
+	if op := e.viPending; op != 0 {
+		e.viPending = 0
+		switch {
+		case op == 'r':
+			if e.pos < len(e.buf) {
+				e.buf[e.pos] = key
+			}
+		case key == op: // dd、cc 删除整行
+			e.deleteRange(0, len(e.buf), true)
+		case key == 'e':
+			e.deleteRange(e.pos, min(e.wordEnd(e.pos)+1, len(e.buf)), true)
+		case key == 'w' && op == 'c': // 和vim一样，cw不删除单词后的空白
+			end := e.pos
+			for end < len(e.buf) && !unicode.IsSpace(e.buf[end]) {
+				end++
+			}
+			e.deleteRange(e.pos, end, true)
+		case key == 'w':
+			e.deleteRange(e.pos, e.wordRight(e.pos), true)
+		case key == 'b':
+			e.deleteRange(e.wordLeft(e.pos), e.pos, true)
+		case key == '$':
+			e.deleteRange(e.pos, len(e.buf), true)
+		case key == '0':
+			e.deleteRange(0, e.pos, true)
+		}
+		if op == 'c' {
+			e.viNormal = false
+		}
+		e.clampViCursor()
+		return
+	}
+
+	switch key {
+	case 'h':
+		if e.pos > 0 {
+			e.pos--
+		}
+	case 'l', ' ':
+		e.pos++
+	case '0':
+		e.pos = 0
+	case '^':
+		e.pos = 0
+		for e.pos < len(e.buf) && unicode.IsSpace(e.buf[e.pos]) {
+			e.pos++
+		}
+	case '$':
+		e.pos = len(e.buf)
+	case 'w':
+		e.pos = e.wordRight(e.pos)
+	case 'b':
+		e.pos = e.wordLeft(e.pos)
+	case 'e':
+		e.pos = e.wordEnd(e.pos)
+	case 'k':
+		e.historyMove(-1)
+	case 'j':
+		e.historyMove(1)
+	case 'x':
+		e.deleteRange(e.pos, e.pos+1, true)
+	case 'X':
+		e.deleteRange(e.pos-1, e.pos, true)
+	case 'D':
+		e.deleteRange(e.pos, len(e.buf), true)
+	case 'C':
+		e.deleteRange(e.pos, len(e.buf), true)
+		e.viNormal = false
+	case 'S':
+		e.deleteRange(0, len(e.buf), true)
+		e.viNormal = false
+	case 'd', 'c', 'r':
+		e.viPending = key
+	case 'p':
+		if len(e.buf) > 0 {
+			e.pos++
+		}
+		e.insert(e.killed)
+		e.pos--
+	case 'P':
+		e.insert(e.killed)
+		e.pos--
+	case 'i':
+		e.viNormal = false
+	case 'a':
+		e.viNormal = false
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case 'I':
+		e.viNormal = false
+		e.pos = 0
+	case 'A':
+		e.viNormal = false
+		e.pos = len(e.buf)
+	}
+	e.clampViCursor()
+}
+
+// clampViCursor 普通模式下光标停在最后一个字符上，而不是行尾之后
+func (e *LineEditor) clampViCursor() {
+	if e.pos < 0 {
+		e.pos = 0
+	}
+	max := len(e.buf)
+	if e.viNormal && max > 0 {
+		max--
+	}
+	if e.pos > max {
+		e.pos = max
+	}
+}
+
+func (e *LineEditor) insert(runes []rune) {
+	if len(runes) == 0 {
+		return
+	}
+	buf := make([]rune, 0, len(e.buf)+len(runes))
+	buf = append(buf, e.buf[:e.pos]...)
+	buf = append(buf, runes...)
+	buf = append(buf, e.buf[e.pos:]...)
+	e.buf = buf
+	e.pos += len(runes)
+}
+
+// deleteRange 删除[from, to)之间的字符，kill为true时保存到粘贴缓冲区
+func (e *LineEditor) deleteRange(from, to int, kill bool) {
+	if from < 0 {
+		from = 0
+	}
+	if to > len(e.buf) {
+		to = len(e.buf)
+	}
+	if from >= to {
+		return
+	}
+	if kill {
+		e.killed = append([]rune(nil), e.buf[from:to]...)
+	}
+	e.buf = append(e.buf[:from], e.buf[to:]...)
+	e.pos = from
+}
+
+func (e *LineEditor) wordLeft(pos int) int {
+	for pos > 0 && unicode.IsSpace(e.buf[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(e.buf[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func (e *LineEditor) wordRight(pos int) int {
+	for pos < len(e.buf) && !unicode.IsSpace(e.buf[pos]) {
+		pos++
+	}
+	for pos < len(e.buf) && unicode.IsSpace(e.buf[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// wordEnd 返回pos之后下一个单词的最后一个字符，与vi的e一样光标已在词尾时跳到下一个词
+func (e *LineEditor) wordEnd(pos int) int {
+	pos++
+	for pos < len(e.buf) && unicode.IsSpace(e.buf[pos]) {
+		pos++
+	}
+	for pos+1 < len(e.buf) && !unicode.IsSpace(e.buf[pos+1]) {
+		pos++
+	}
+	return pos
+}
+
+// historyMove 在历史记录中上下移动，回到最下面时恢复正在编辑的内容
+func (e *LineEditor) historyMove(delta int) {
+	index := e.histIndex + delta
+	if index < 0 || index > len(e.history) {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+	if e.histIndex == len(e.history) {
+		e.saved = append([]rune(nil), e.buf...)
+	}
+	e.histIndex = index
+	if index == len(e.history) {
+		e.buf = append([]rune(nil), e.saved...)
+	} else {
+		e.buf = []rune(e.history[index])
+	}
+	e.pos = len(e.buf)
+	e.clampViCursor()
+}
+
+// complete 补全光标前的单词：唯一候选直接补全，多个候选先补全公共前缀，连按两次Tab列出所有候选
+func (e *LineEditor) complete() {
+	if e.Complete == nil {
+		return
+	}
+	line := string(e.buf)
+	bytePos := len(string(e.buf[:e.pos]))
+	start, candidates := e.Complete(line, bytePos)
+	if len(candidates) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+
+	word := line[start:bytePos]
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		prefix = commonPrefix(prefix, c)
+	}
+	if len(prefix) > len(word) || (len(candidates) == 1 && prefix != word) {
+		e.buf = []rune(line[:start] + prefix + line[bytePos:])
+		e.pos = len([]rune(line[:start] + prefix))
+		e.lastTab = len(candidates) > 1 // 还有多个候选时，再按一次Tab列出
+		e.refresh()
+		return
+	}
+	if !e.lastTab {
+		e.lastTab = true
+		fmt.Fprint(e.out, "\a")
+		return
+	}
+
+	e.pos = len(e.buf)
+	e.refresh()
+	fmt.Fprint(e.out, "\n")
+	e.printCandidates(candidates)
+	e.cursorRow = 0
+	e.pos = len([]rune(line[:bytePos]))
+	e.refresh()
+}
+
+// printCandidates 按列输出补全候选
+func (e *LineEditor) printCandidates(candidates []string) {
+	width := 0
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = candidateName(c)
+		if w := stringWidth(names[i]); w > width {
+			width = w
+		}
+	}
+	cols := terminalWidth()
+	if cols <= 0 {
+		cols = 80
+	}
+	perRow := cols / (width + 2)
+	if perRow < 1 {
+		perRow = 1
+	}
+	for i, name := range names {
+		fmt.Fprint(e.out, padRight(name, width+2))
+		if (i+1)%perRow == 0 || i == len(names)-1 {
+			fmt.Fprint(e.out, "\n")
+		}
+	}
+}
+
+// candidateName 列出候选项时显示的名称：去掉引号和转义，路径只显示最后一级，URL完整显示
+func candidateName(c string) string {
+	c, _ = unquoteWord(c)
+	if strings.Contains(c, "://") {
+		return c
+	}
+	trimmed := strings.TrimRight(c, "/")
+	if i := strings.LastIndex(trimmed, "/"); i >= 0 {
+		return c[i+1:]
+	}
+	return c
+}
+
+func commonPrefix(a, b string) string {
+	ar, br := []rune(a), []rune(b)
+	n := 0
+	for n < len(ar) && n < len(br) && ar[n] == br[n] {
+		n++
+	}
+	return string(ar[:n])
+}
+
+// reverseSearch Ctrl+R反向搜索历史记录。返回非0按键时由调用方继续处理
+func (e *LineEditor) reverseSearch() (rune, error) {
+	origBuf, origPos := append([]rune(nil), e.buf...), e.pos
+	var query []rune
+	match := len(e.history)
+	failed := false
+
+	search := func(from int) {
+		q := string(query)
+		for i := from; i >= 0; i-- {
+			if i >= len(e.history) {
+				continue
+			}
+			if idx := strings.LastIndex(e.history[i], q); idx >= 0 {
+				match = i
+				e.buf = []rune(e.history[i])
+				e.pos = len([]rune(e.history[i][:idx]))
+				failed = false
+				return
+			}
+		}
+		failed = true
+	}
+
+	for {
+		label := "reverse-i-search"
+		if failed {
+			label = "failed reverse-i-search"
+		}
+		e.render(fmt.Sprintf("(%s)`%s': ", label, string(query)))
+
+		key, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case key == ctrlR:
+			search(match - 1)
+		case key == backspace || key == ctrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				search(len(e.history) - 1)
+			}
+		case key == ctrlG || key == keyEsc:
+			e.buf, e.pos = origBuf, origPos
+			e.refresh()
+			return 0, nil
+		case key == ctrlC:
+			return ctrlC, nil
+		case key == enter || key == ctrlJ:
+			return enter, nil
+		case key >= 32:
+			query = append(query, key)
+			search(match)
+		default:
+			// 其他按键结束搜索，保留匹配结果并继续处理该按键
+			e.refresh()
+			return key, nil
+		}
+	}
+}
+
+func (e *LineEditor) refresh() {
+	e.render(e.prompt)
+}
+
+// render 重绘提示符和当前行，按显示宽度计算折行，保证中文等宽字符和多行内容的光标位置正确
+func (e *LineEditor) render(prompt string) {
+	cols := terminalWidth()
+	if cols <= 0 {
+		cols = 80
+	}
+
+	var b strings.Builder
+	if e.cursorRow > 0 {
+		fmt.Fprintf(&b, "\033[%dA", e.cursorRow)
+	}
+	b.WriteString("\r\033[J")
+	b.WriteString(prompt)
+	b.WriteString(string(e.buf))
+
+	promptRow, promptCol := layout(0, 0, []rune(stripANSI(prompt)), cols)
+	endRow, endCol := layout(promptRow, promptCol, e.buf, cols)
+	// 正好写满一行时终端不会自动换行，手动换行使光标位置可预测
+	if endCol == cols {
+		b.WriteString("\n")
+		endRow++
+	}
+	row, col := layout(promptRow, promptCol, e.buf[:e.pos], cols)
+	if col == cols {
+		row, col = row+1, 0
+	}
+	if endRow > row {
+		fmt.Fprintf(&b, "\033[%dA", endRow-row)
+	}
+	b.WriteString("\r")
+	if col > 0 {
+		fmt.Fprintf(&b, "\033[%dC", col)
+	}
+	e.cursorRow = row
+	io.WriteString(e.out, b.String())
+}
+
+// layout 计算从(row, col)开始输出text后光标所在的位置，col等于cols表示停在行尾等待折行
+func layout(row, col int, text []rune, cols int) (int, int) {
+	for _, r := range text {
+		switch r {
+		case '\n':
+			row, col = row+1, 0
+			continue
+		case '\t':
+			if col == cols {
+				row, col = row+1, 0
+			}
+			col = min((col/8+1)*8, cols-1)
+			continue
+		}
+		w := runeWidth(r)
+		if col+w > cols {
+			row, col = row+1, 0
+		}
+		col += w
+	}
+	return row, col
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineEditorWordEnd(t *testing.T) {
+	tests := []struct {
+		buf  string
+		pos  int
+		want int
+	}{
+		{"foo bar", 0, 2},
+		{"foo bar", 1, 2},
+		{"foo bar", 2, 6},
+		{"foo   bar", 3, 8},
+		{"a b", 0, 2},
+		{"foo bar", 6, 7}, // 已在最后一个字符，由clampViCursor留在原地
+		{"foo   ", 2, 6},
+	}
+	for _, tt := range tests {
+		e := &LineEditor{buf: []rune(tt.buf)}
+		if got := e.wordEnd(tt.pos); got != tt.want {
+			t.Errorf("wordEnd(%q, %d) = %d, want %d", tt.buf, tt.pos, got, tt.want)
+		}
+	}
+}
+
+// editKeys 把keys作为按键输入给编辑器，没有回车时在输入结束后返回io.EOF，此时可以检查buf和pos
+func editKeys(e *LineEditor, keys string) (string, error) {
+	e.reader = bufio.NewReader(strings.NewReader(keys))
+	e.out = io.Discard
+	return e.edit("> ")
+}
+
+type editorTest struct {
+	keys string
+	buf  string
+	pos  int
+}
+
+func runEditorTests(t *testing.T, e *LineEditor, tests []editorTest) {
+	t.Helper()
+	for _, tt := range tests {
+		if _, err := editKeys(e, tt.keys); err != io.EOF {
+			t.Errorf("keys %q: err = %v, want io.EOF", tt.keys, err)
+			continue
+		}
+		if string(e.buf) != tt.buf || e.pos != tt.pos {
+			t.Errorf("keys %q: buf = %q, pos = %d, want %q, %d", tt.keys, string(e.buf), e.pos, tt.buf, tt.pos)
+		}
+	}
+}
+
+func TestLineEditorReadLine(t *testing.T) {
+	tests := []struct {
+		keys string
+		line string
+		err  error
+	}{
+		{"hello\r", "hello", nil},
+		{"hello\n", "hello", nil},
+		{"ab\x02\r", "ab", nil},
+		{"abc\x03", "", errInterrupted},
+		{"\x04", "", io.EOF},
+		{"ab\x01\x04\r", "b", nil},
+		{"abc", "", io.EOF},
+	}
+	for _, tt := range tests {
+		line, err := editKeys(&LineEditor{}, tt.keys)
+		if line != tt.line || err != tt.err {
+			t.Errorf("keys %q = %q, %v, want %q, %v", tt.keys, line, err, tt.line, tt.err)
+		}
+	}
+}
+
+func TestLineEditorEmacs(t *testing.T) {
+	runEditorTests(t, &LineEditor{}, []editorTest{
+		{"abc\x02\x02X", "aXbc", 2},
+		{"abc\x01X\x05Y", "XabcY", 5},
+		{"abc\x1b[D\x1b[DX\x1b[C", "aXbc", 3},
+		{"abc\x1b[H\x1b[3~", "bc", 0},
+		{"abc\x1b[D\x1b[F", "abc", 3},
+		{"abc\x7f", "ab", 2},
+		{"abc\x02\x02\x0b", "a", 1},
+		{"abc\x02\x15", "c", 0},
+		{"foo bar\x17", "foo ", 4},
+		{"foo bar\x17\x01\x19", "barfoo ", 3},
+		{"foo bar\x1b\x7f", "foo ", 4},
+		{"foo bar\x1bb\x1bd", "foo ", 4},
+		{"foo bar\x01\x1bf", "foo bar", 4},
+		{"foo bar baz\x1b[1;5D\x1b[1;5DX", "foo Xbar baz", 5},
+		{"ab\x14", "ba", 2},
+		{"abc\x02\x14", "acb", 3},
+		{"日本語\x02\x02X", "日X本語", 2},
+		{"ab\x1b[Z", "ab", 2},
+	})
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	e := &LineEditor{}
+	e.SetHistory([]string{"ls", "cat a.txt", "pwd"})
+	runEditorTests(t, e, []editorTest{
+		{"\x1b[A", "pwd", 3},
+		{"\x1b[A\x1b[A", "cat a.txt", 9},
+		{"\x10\x10\x10\x10", "ls", 2}, // 已经是最早的一条
+		{"\x10\x10\x0e", "pwd", 3},
+		{"draft\x1b[A\x1b[B", "draft", 5},
+		{"draft\x1b[A\x1b[A\x0e\x0e", "draft", 5},
+		{"\x0e", "", 0},
+		{"\x1b[AX", "pwdX", 4},
+	})
+}
+
+func TestLineEditorReverseSearch(t *testing.T) {
+	e := &LineEditor{}
+	e.SetHistory([]string{"git status", "go test ./...", "git commit -m x", "ls"})
+	runEditorTests(t, e, []editorTest{
+		{"\x12git", "git commit -m x", 0},
+		{"\x12status", "git status", 4},
+		{"\x12git\x12", "git status", 0},
+		{"\x12git\x12\x12", "git status", 0}, // 没有更早的匹配时保留上一个
+		{"\x12git\x7f\x7f\x7fl", "ls", 0},
+		{"\x12zzz", "", 0},
+		{"draft\x12git\x07", "draft", 5},
+		{"draft\x02\x12git\x1b", "draft", 4},
+		// 其他按键结束搜索后继续生效
+		{"\x12tes\x05!", "go test ./...!", 14},
+		{"\x12status\x02X", "gitX status", 4},
+	})
+
+	for _, tt := range []struct {
+		keys string
+		line string
+		err  error
+	}{
+		{"\x12test\r", "go test ./...", nil},
+		{"\x12test\x03", "", errInterrupted},
+	} {
+		line, err := editKeys(e, tt.keys)
+		if line != tt.line || err != tt.err {
+			t.Errorf("keys %q = %q, %v, want %q, %v", tt.keys, line, err, tt.line, tt.err)
+		}
+	}
+}
+
+func TestLineEditorPaste(t *testing.T) {
+	runEditorTests(t, &LineEditor{}, []editorTest{
+		// 粘贴内容中的换行不会提交输入，结尾的换行被去掉
+		{"\x1b[200~line1\r\nline2\n\x1b[201~", "line1\nline2", 11},
+		{"\x1b[200~a\rb\x1b[201~!", "a\nb!", 4},
+		{"ab\x02\x1b[200~X|Y\x1b[201~", "aX|Yb", 4},
+		{"\x1b[200~\x1b[201~", "", 0},
+	})
+	line, err := editKeys(&LineEditor{}, "\x1b[200~one\ntwo\x1b[201~\r")
+	if line != "one\ntwo" || err != nil {
+		t.Errorf("pasted line = %q, %v, want %q", line, err, "one\ntwo")
+	}
+
+	// vi普通模式下粘贴后光标停在最后一个字符上
+	e := &LineEditor{ViMode: true}
+	runEditorTests(t, e, []editorTest{
+		{"ab\x1b0\x1b[200~XY\x1b[201~", "XYab", 2},
+	})
+}
+
+func TestLineEditorVi(t *testing.T) {
+	e := &LineEditor{ViMode: true}
+	e.SetHistory([]string{"one", "two"})
+	// ESC后紧跟b、d、f等按键会被当作Alt组合键，这里先用0或h等移动光标
+	runEditorTests(t, e, []editorTest{
+		{"hello", "hello", 5},
+		{"hello\x1b0", "hello", 0},
+		{"hello\x1bh", "hello", 3},
+		{"hello\x1b0ix", "xhello", 1},
+		{"hello\x1b0ax", "hxello", 2},
+		{"hello\x1b0AX", "helloX", 6},
+		{"hello\x1bhIX", "Xhello", 1},
+		{"abc\x1b0$iX", "abXc", 3},
+		{"  abc\x1b0^", "  abc", 2},
+		{"foo bar\x1b0w", "foo bar", 4},
+		{"foo bar\x1b0e", "foo bar", 2},
+		{"foo bar\x1b0we", "foo bar", 6},
+		{"foo bar\x1b$b", "foo bar", 4},
+		{"foo bar\x1b0ll", "foo bar", 2},
+		{"foo bar\x1b0$l", "foo bar", 6},
+		{"foo bar\x1bx", "foo ba", 5},
+		{"foo bar\x1bX", "foo br", 5},
+		{"foo bar\x1b0dw", "bar", 0},
+		{"foo bar\x1b0de", " bar", 0},
+		{"foo bar\x1b0cwbaz", "baz bar", 3},
+		{"foo bar\x1b0ceX", "X bar", 1},
+		{"foo bar\x1b0wd$", "foo ", 3},
+		{"foo bar\x1b0wd0", "bar", 0},
+		{"foo bar\x1b0wdb", "bar", 0},
+		{"foo bar\x1b0dd", "", 0},
+		{"foo bar\x1b0ccnew", "new", 3},
+		{"foo bar\x1b0wD", "foo ", 3},
+		{"foo bar\x1b0wCbaz", "foo baz", 7},
+		{"foo bar\x1b0Snew", "new", 3},
+		{"abc\x1b0rX", "Xbc", 0},
+		{"abc\x1b0xp", "bac", 1},
+		{"abc\x1b0xP", "abc", 0},
+		{"abc\x1b0x$p", "bca", 2},
+		{"\x1b0k", "two", 2},
+		{"\x1b0kk", "one", 2},
+		{"\x1b0kkj", "two", 2},
+		{"x\x1b0kj", "x", 0},
+		{"abc\x1b0\x7f", "abc", 0},
+		{"abc\x1b0\x1b", "abc", 0},
+	})
+}
+
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		row, col int
+		text     string
+		cols     int
+		wantRow  int
+		wantCol  int
+	}{
+		{0, 0, "ab", 80, 0, 2},
+		{0, 2, "日本", 80, 0, 6},
+		{0, 0, "日本語", 5, 1, 2}, // 宽字符放不下时整个移到下一行
+		{0, 0, "abcd日", 5, 1, 2},
+		{0, 0, "abcde", 5, 0, 5}, // 正好写满时停在行尾
+		{0, 0, "abcdef", 5, 1, 1},
+		{0, 3, "a\nb", 80, 1, 1},
+		{0, 0, "a\tb", 80, 0, 9},
+		{0, 0, "abcd\t", 5, 0, 4},
+		{0, 0, "abcde\t", 5, 1, 4},
+		{1, 0, "你好，世界", 4, 3, 2},
+	}
+	for _, tt := range tests {
+		row, col := layout(tt.row, tt.col, []rune(tt.text), tt.cols)
+		if row != tt.wantRow || col != tt.wantCol {
+			t.Errorf("layout(%d, %d, %q, %d) = %d, %d, want %d, %d",
+				tt.row, tt.col, tt.text, tt.cols, row, col, tt.wantRow, tt.wantCol)
+		}
+	}
+}
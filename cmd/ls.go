@@ -2,61 +2,503 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
 )
 
+// LsOptions ls命令的选项，flag标签用于/help和Tab补全
+type LsOptions struct {
+	All           bool   `flag:"-a,--all" help:"显示以.开头的文件，包括.和.."`
+	AlmostAll     bool   `flag:"-A,--almost-all" help:"显示以.开头的文件，不包括.和.."`
+	Directory     bool   `flag:"-d,--directory" help:"列出目录本身而不是目录内容"`
+	HumanReadable bool   `flag:"-h,--human-readable" help:"以K、M、G显示文件大小"`
+	Long          bool   `flag:"-l,--long" help:"显示详细信息"`
+	Reverse       bool   `flag:"-r,--reverse" help:"倒序排列"`
+	Recursive     bool   `flag:"-R,--recursive" help:"递归列出子目录"`
+	SortSize      bool   `flag:"-S,--sort-size" help:"按文件大小排序，大的在前"`
+	SortTime      bool   `flag:"-t,--sort-time" help:"按修改时间排序，新的在前"`
+	OneColumn     bool   `flag:"-1,--one-column" help:"每行显示一个文件"`
+	Color         string `flag:"--color" arg:"WHEN" help:"文件名颜色: always|auto|never"`
+	Summarize     bool   `flag:"-s,--summarize" help:"让AI总结目录内容"`
+}
+
+func newLsOptions() *LsOptions {
+	return &LsOptions{Color: "auto"}
+}
+
+// parseLsArgs 解析ls的选项和路径，短选项可以合并写，如 -lah，-- 之后都是路径
+func parseLsArgs(args []string) (*LsOptions, []string, error) {
+	options := newLsOptions()
+	long := map[string]*bool{
+		"--all":            &options.All,
+		"--almost-all":     &options.AlmostAll,
+		"--directory":      &options.Directory,
+		"--human-readable": &options.HumanReadable,
+		"--long":           &options.Long,
+		"--reverse":        &options.Reverse,
+		"--recursive":      &options.Recursive,
+		"--sort-size":      &options.SortSize,
+		"--sort-time":      &options.SortTime,
+		"--one-column":     &options.OneColumn,
+		"--summarize":      &options.Summarize,
+	}
+	short := map[rune]*bool{
+		'a': &options.All, 'A': &options.AlmostAll, 'd': &options.Directory, 'h': &options.HumanReadable,
+		'l': &options.Long, 'r': &options.Reverse, 'R': &options.Recursive, 'S': &options.SortSize,
+		't': &options.SortTime, '1': &options.OneColumn, 's': &options.Summarize,
+	}
+
+	var paths []string
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return options, append(paths, args[i+1:]...), nil
+		case arg == "--color":
+			options.Color = "always"
+		case strings.HasPrefix(arg, "--color="):
+			options.Color = strings.TrimPrefix(arg, "--color=")
+		case strings.HasPrefix(arg, "--"):
+			p, ok := long[arg]
+			if !ok {
+				return nil, nil, fmt.Errorf(T("未知选项: %s"), arg)
+			}
+			*p = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for _, c := range arg[1:] {
+				p, ok := short[c]
+				if !ok {
+					return nil, nil, fmt.Errorf(T("未知选项: -%c"), c)
+				}
+				*p = true
+			}
+		default:
+			paths = append(paths, arg)
+		}
+	}
+	return options, paths, nil
+}
+
 // HandleLs 处理ls/ll命令，列出目录内容
-func HandleLs(prompt string, processQuery func(string, bool)) {
-	isLl := strings.HasPrefix(prompt, "ll")
-	showDetails := isLl || strings.Contains(prompt, "-l") || strings.Contains(prompt, "-lh")
-	humanReadable := isLl && strings.Contains(prompt, "-h") || strings.Contains(prompt, "-h") || strings.Contains(prompt, "-lh")
-	shouldSummarize := strings.ContainsAny(prompt, "s") && (strings.Contains(prompt, "-s") || strings.Contains(prompt, "-ls") ||
-		strings.Contains(prompt, "-hs") || strings.Contains(prompt, "-lhs") || strings.Contains(prompt, "-lls"))
-
-	// 列出当前目录
-	files, err := os.ReadDir(".")
+func HandleLs(prompt string, w io.Writer, processQuery func(string, bool)) {
+	args, err := splitArgs(prompt)
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	options, paths, err := parseLsArgs(args[1:])
 	if err != nil {
-		fmt.Printf("无法读取目录: %v\n", err)
+		fmt.Printf(T("参数解析失败: %v\n"), err)
 		return
 	}
+	options.Long = options.Long || args[0] == "ll"
+	if err := runLs(paths, options, w, processQuery); err != nil && err != errReported {
+		fmt.Println(err)
+	}
+}
 
-	var output strings.Builder
-	for _, file := range files {
-		if !showDetails {
-			fmt.Fprintln(&output, file.Name())
-		} else {
-			// 详细格式
-			info, err := file.Info()
-			if err != nil {
-				fmt.Printf("无法获取文件信息: %v\n", err)
-				continue
-			}
+// stdoutTerminal 程序启动时标准输出是否是终端。REPL记录命令输出时会临时替换os.Stdout，
+// 所以需要在启动时判断
+var stdoutTerminal = isTerminal(os.Stdout)
 
-			size := info.Size()
-			sizeStr := fmt.Sprintf("%8d", size)
-			if humanReadable {
-				sizeStr = formatSize(size)
-			}
+// lsEntry 要列出的一个文件
+type lsEntry struct {
+	name string // 显示的名称
+	path string // 文件路径，用于读取链接目标和递归
+	info os.FileInfo
+}
+
+// lsLister 按选项把文件列表写入out，出错的路径输出错误后继续
+type lsLister struct {
+	options *LsOptions
+	out     strings.Builder
+	color   bool
+	columns int // 多列显示时的终端宽度，0表示每行一个
+	failed  bool
+}
+
+// runLs 列出各个路径，没有路径时列出当前目录。与GNU ls一样先列出文件，再逐个列出目录。
+// 无法访问的路径输出错误后继续，有失败时返回errReported
+func runLs(paths []string, options *LsOptions, w io.Writer, processQuery func(string, bool)) error {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	color, err := colorEnabled(options.Color, w)
+	if err != nil {
+		return err
+	}
+	l := &lsLister{options: options, color: color}
+	if w == os.Stdout && stdoutTerminal && !options.Long && !options.OneColumn {
+		l.columns = lsWidth()
+	}
 
-			// 格式化输出：权限 大小 修改时间 文件名
-			fmt.Fprintf(&output, "%s %8s %s %s\n",
-				info.Mode().String(),
-				sizeStr,
-				info.ModTime().Format("Jan _2 15:04"),
-				file.Name())
+	var files, dirs []lsEntry
+	for _, path := range paths {
+		// 详细格式和 -d 显示符号链接本身，否则跟随链接列出目录内容
+		stat := os.Stat
+		if options.Long || options.Directory {
+			stat = os.Lstat
+		}
+		info, err := stat(path)
+		if err != nil {
+			l.fail(err)
+			continue
+		}
+		entry := lsEntry{name: path, path: path, info: info}
+		if info.IsDir() && !options.Directory {
+			dirs = append(dirs, entry)
+		} else {
+			files = append(files, entry)
 		}
 	}
+	l.sort(files)
+	l.sort(dirs)
+	l.write(files)
+	header := len(paths) > 1 || options.Recursive
+	for _, dir := range dirs {
+		l.listDir(dir.path, header)
+	}
 
 	// 总是先打印原始结果
-	fmt.Print(output.String())
+	fmt.Fprint(w, l.out.String())
 
 	// 如果需要总结，发送给AI
-	if shouldSummarize {
-		summaryPrompt := fmt.Sprintf("请总结以下文件列表:\n%s\n用中文简洁概括目录内容", output.String())
-		fmt.Println("AI总结:")
+	if options.Summarize && l.out.Len() > 0 {
+		summaryPrompt, err := renderPrompt("ls-summary", map[string]string{"content": stripANSI(l.out.String())})
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		fmt.Fprintln(w, T("AI总结:"))
 		processQuery(summaryPrompt, true)
 	}
+	if l.failed {
+		return errReported
+	}
+	return nil
+}
+
+func (l *lsLister) fail(err error) {
+	fmt.Printf("ls: %v\n", err)
+	l.failed = true
+}
+
+// listDir 列出目录内容，-R 时继续列出子目录。header为true时先输出目录名
+func (l *lsLister) listDir(dir string, header bool) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		l.fail(err)
+		return
+	}
+	var entries []lsEntry
+	if l.options.All {
+		for _, name := range []string{".", ".."} {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				entries = append(entries, lsEntry{name: name, path: lsJoin(dir, name), info: info})
+			}
+		}
+	}
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".") && !l.options.All && !l.options.AlmostAll {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			l.fail(err)
+			continue
+		}
+		entries = append(entries, lsEntry{name: file.Name(), path: lsJoin(dir, file.Name()), info: info})
+	}
+	l.sort(entries)
+
+	if header {
+		if l.out.Len() > 0 {
+			l.out.WriteString("\n")
+		}
+		fmt.Fprintf(&l.out, "%s:\n", dir)
+	}
+	if l.options.Long {
+		var total int64
+		for _, e := range entries {
+			_, _, _, blocks := fileStat(e.info)
+			total += blocks
+		}
+		fmt.Fprintf(&l.out, "total %s\n", l.size(total*1024, strconv.FormatInt(total, 10)))
+	}
+	l.write(entries)
+
+	if !l.options.Recursive {
+		return
+	}
+	for _, e := range entries {
+		// 不进入符号链接指向的目录，避免循环
+		if e.info.IsDir() && e.name != "." && e.name != ".." {
+			l.listDir(e.path, true)
+		}
+	}
+}
+
+// sort 默认按名称排序，-t 按修改时间、-S 按大小从大到小，-r 倒序
+func (l *lsLister) sort(entries []lsEntry) {
+	less := func(a, b lsEntry) bool { return a.name < b.name }
+	switch {
+	case l.options.SortSize:
+		less = func(a, b lsEntry) bool {
+			if a.info.Size() != b.info.Size() {
+				return a.info.Size() > b.info.Size()
+			}
+			return a.name < b.name
+		}
+	case l.options.SortTime:
+		less = func(a, b lsEntry) bool {
+			if !a.info.ModTime().Equal(b.info.ModTime()) {
+				return a.info.ModTime().After(b.info.ModTime())
+			}
+			return a.name < b.name
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if l.options.Reverse {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+}
+
+// write 按详细格式、多列或每行一个输出文件
+func (l *lsLister) write(entries []lsEntry) {
+	switch {
+	case len(entries) == 0:
+	case l.options.Long:
+		l.writeLong(entries)
+	case l.columns > 0:
+		l.writeColumns(entries)
+	default:
+		for _, e := range entries {
+			fmt.Fprintln(&l.out, l.name(e))
+		}
+	}
+}
+
+// writeLong 详细格式: 权限 链接数 属主 属组 大小 修改时间 文件名，各列对齐
+func (l *lsLister) writeLong(entries []lsEntry) {
+	rows := make([][6]string, len(entries))
+	var width [6]int
+	for i, e := range entries {
+		nlink, owner, group, _ := fileStat(e.info)
+		rows[i] = [6]string{
+			lsMode(e.info.Mode()),
+			strconv.FormatUint(nlink, 10),
+			owner,
+			group,
+			l.size(e.info.Size(), strconv.FormatInt(e.info.Size(), 10)),
+			lsTime(e.info.ModTime()),
+		}
+		for j, s := range rows[i] {
+			width[j] = max(width[j], len(s))
+		}
+	}
+	for i, e := range entries {
+		r := rows[i]
+		fmt.Fprintf(&l.out, "%s %*s ", r[0], width[1], r[1])
+		// 不支持属主的平台上不显示这两列
+		if width[2] > 0 {
+			fmt.Fprintf(&l.out, "%-*s %-*s ", width[2], r[2], width[3], r[3])
+		}
+		fmt.Fprintf(&l.out, "%*s %s %s", width[4], r[4], r[5], l.name(e))
+		if e.info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Readlink(e.path); err == nil {
+				fmt.Fprintf(&l.out, " -> %s", target)
+			}
+		}
+		l.out.WriteString("\n")
+	}
+}
+
+// writeColumns 与GNU ls一样按列排列文件名，在终端宽度内使用尽量多的列
+func (l *lsLister) writeColumns(entries []lsEntry) {
+	widths := make([]int, len(entries))
+	for i, e := range entries {
+		widths[i] = stringWidth(e.name)
+	}
+	rows := len(entries)
+	var colWidths []int
+	for cols := len(entries); cols > 1; cols-- {
+		n := (len(entries) + cols - 1) / cols
+		ws := make([]int, (len(entries)+n-1)/n)
+		total := 0
+		for c := range ws {
+			for r := 0; r < n && c*n+r < len(entries); r++ {
+				ws[c] = max(ws[c], widths[c*n+r])
+			}
+			total += ws[c] + 2
+		}
+		if total-2 <= l.columns {
+			rows, colWidths = n, ws
+			break
+		}
+	}
+	for r := 0; r < rows; r++ {
+		var line strings.Builder
+		for c := 0; c < max(len(colWidths), 1); c++ {
+			i := c*rows + r
+			if i >= len(entries) {
+				break
+			}
+			if c > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(l.name(entries[i]))
+			if c+1 < len(colWidths) && i+rows < len(entries) {
+				line.WriteString(strings.Repeat(" ", colWidths[c]-widths[i]))
+			}
+		}
+		fmt.Fprintln(&l.out, line.String())
+	}
+}
+
+// lsJoin 与GNU ls一样直接拼接路径，-R 时 . 下的子目录显示为 ./sub
+func lsJoin(dir, name string) string {
+	if strings.HasSuffix(dir, string(filepath.Separator)) {
+		return dir + name
+	}
+	return dir + string(filepath.Separator) + name
+}
+
+// lsMode 按GNU ls的格式显示文件类型和权限，如 drwxr-xr-x、lrwxrwxrwx、drwxrwxrwt
+func lsMode(mode os.FileMode) string {
+	b := []byte("-rwxrwxrwx")
+	switch {
+	case mode&os.ModeDir != 0:
+		b[0] = 'd'
+	case mode&os.ModeSymlink != 0:
+		b[0] = 'l'
+	case mode&os.ModeNamedPipe != 0:
+		b[0] = 'p'
+	case mode&os.ModeSocket != 0:
+		b[0] = 's'
+	case mode&os.ModeCharDevice != 0:
+		b[0] = 'c'
+	case mode&os.ModeDevice != 0:
+		b[0] = 'b'
+	}
+	for i := 0; i < 9; i++ {
+		if mode&(1<<uint(8-i)) == 0 {
+			b[i+1] = '-'
+		}
+	}
+	special := func(i int, set bool, c byte) {
+		if !set {
+			return
+		}
+		if b[i] == '-' {
+			c -= 'a' - 'A'
+		}
+		b[i] = c
+	}
+	special(3, mode&os.ModeSetuid != 0, 's')
+	special(6, mode&os.ModeSetgid != 0, 's')
+	special(9, mode&os.ModeSticky != 0, 't')
+	return string(b)
+}
+
+// lsColors 与GNU ls默认的LS_COLORS一致
+const (
+	lsColorDir     = "\033[01;34m"
+	lsColorLink    = "\033[01;36m"
+	lsColorExec    = "\033[01;32m"
+	lsColorSpecial = "\033[40;33;01m"
+)
+
+// name 返回文件名，开启颜色时按文件类型着色
+func (l *lsLister) name(e lsEntry) string {
+	if !l.color {
+		return e.name
+	}
+	return colorName(e.name, e.info.Mode())
+}
+
+// colorEnabled 按 --color 的值判断是否着色，auto 时只在输出到终端时着色
+func colorEnabled(when string, w io.Writer) (bool, error) {
+	switch when {
+	case "always":
+		return true, nil
+	case "auto":
+		return w == os.Stdout && stdoutTerminal && os.Getenv("NO_COLOR") == "", nil
+	case "never":
+		return false, nil
+	}
+	return false, fmt.Errorf(T("--color 的值无效: %s (可选 always|auto|never)"), when)
+}
+
+// colorName 按文件类型给文件名着色
+func colorName(name string, mode os.FileMode) string {
+	color := ""
+	switch {
+	case mode&os.ModeSymlink != 0:
+		color = lsColorLink
+	case mode.IsDir():
+		color = lsColorDir
+	case mode&(os.ModeNamedPipe|os.ModeSocket|os.ModeDevice|os.ModeCharDevice) != 0:
+		color = lsColorSpecial
+	case mode&0111 != 0:
+		color = lsColorExec
+	}
+	if color == "" {
+		return name
+	}
+	return color + name + ansiReset
+}
+
+// size 返回大小，-h 时以K、M、G显示，否则使用plain
+func (l *lsLister) size(bytes int64, plain string) string {
+	if l.options.HumanReadable {
+		return formatSize(bytes)
+	}
+	return plain
+}
+
+// lsTime 与GNU ls一样，半年内的文件显示时间，更早或将来的文件显示年份
+func lsTime(t time.Time) string {
+	if d := time.Since(t); d < 0 || d > 182*24*time.Hour {
+		return t.Format("Jan _2  2006")
+	}
+	return t.Format("Jan _2 15:04")
+}
+
+// lsWidth 返回多列显示使用的宽度
+func lsWidth() int {
+	if width := terminalWidth(); width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
+func newLsCmd() *cobra.Command {
+	options := newLsOptions()
+	cmd := &cobra.Command{
+		Use:     "ls [路径]...",
+		Aliases: []string{"ll"},
+		Short:   "列出目录内容，ll 等同于 ls -l",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.Long = options.Long || cmd.CalledAs() == "ll"
+			return runLs(args, options, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	// 与GNU ls一样，--color 不带值时表示always
+	cmd.Flags().Lookup("color").NoOptDefVal = "always"
+	// -h 表示以K、M、G显示大小，帮助只保留 --help
+	cmd.Flags().Bool("help", false, "help for ls")
+	return cmd
 }
 
 func formatSize(size int64) string {
@@ -71,3 +513,19 @@ func formatSize(size int64) string {
 	}
 	return fmt.Sprintf("%.1f%cB", float64(size)/float64(div), "KMGTPE"[exp])
 }
+
+func init() {
+	rootCmd.AddCommand(newLsCmd())
+	registerCommand(&Command{
+		Name:     "ls",
+		Aliases:  []string{"ll"},
+		Usage:    "[-aAdhlrRSt1s] [路径...]",
+		Help:     "列出目录内容，ll 等同于 ls -l",
+		Flags:    optionFlags(LsOptions{}),
+		Record:   true,
+		Complete: completeFiles,
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleLs(input, stdout, r.Query(stdout))
+		},
+	})
+}
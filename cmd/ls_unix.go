@@ -0,0 +1,51 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// 属主和属组名称的缓存，避免每个文件都查询一次
+var (
+	lsUsers  = map[uint32]string{}
+	lsGroups = map[uint32]string{}
+)
+
+// fileStat 返回文件的硬链接数、属主、属组和占用的1K块数
+func fileStat(info os.FileInfo) (nlink uint64, owner, group string, blocks int64) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 1, "", "", (info.Size() + 1023) / 1024
+	}
+	return uint64(st.Nlink), userName(uint32(st.Uid)), groupName(uint32(st.Gid)), int64(st.Blocks) / 2
+}
+
+// userName 返回uid对应的用户名，查不到时返回uid
+func userName(uid uint32) string {
+	if name, ok := lsUsers[uid]; ok {
+		return name
+	}
+	name := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	lsUsers[uid] = name
+	return name
+}
+
+// groupName 返回gid对应的组名，查不到时返回gid
+func groupName(gid uint32) string {
+	if name, ok := lsGroups[gid]; ok {
+		return name
+	}
+	name := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(name); err == nil {
+		name = g.Name
+	}
+	lsGroups[gid] = name
+	return name
+}
@@ -0,0 +1,413 @@
+package cmd
+
+// enMessages 英文界面文字，键是代码中去掉末尾换行的中文原文
+var enMessages = map[string]string{
+	// ai.go
+	"读取管道输入失败: %v":               "Failed to read piped input: %v",
+	`用法: ai "问题"，或 命令 | ai "问题"`: "Usage: ai \"question\", or command | ai \"question\"",
+	"[问题]": "[question]",
+	"向AI提问，在管道中使用时上一个命令的输出作为上下文": "Ask the AI, in a pipeline the previous command's output is the context",
+
+	// batch.go
+	"%s:%d: 需要 prompt 或 template 字段": "%s:%d: a prompt or template field is required",
+	"共 %d 项，已完成 %d 项，待处理 %d 项":       "%d items, %d done, %d pending",
+	"失败: ": "failed: ",
+	"%d 项处理失败，可使用 --resume 重试": "%d items failed, retry them with --resume",
+	"--workers 必须大于0":          "--workers must be greater than 0",
+	"--retries 不能小于0":          "--retries must not be negative",
+	"批量处理JSONL中的提示词":           "Process the prompts in a JSONL file in batch",
+	`批量处理JSONL文件，每行一个请求:
+  {"id": "t-1", "prompt": "..."}
+  {"id": "t-2", "template": "classify", "vars": {"input": "..."}}
+结果按输入顺序写入输出文件，失败的请求会自动重试`: "Process a JSONL file with one request per line:\n  {\"id\": \"t-1\", \"prompt\": \"...\"}\n  {\"id\": \"t-2\", \"template\": \"classify\", \"vars\": {\"input\": \"...\"}}\nResults are written to the output file in input order, failed requests are retried automatically",
+	"输出文件": "output file",
+	"并发数":  "number of concurrent requests",
+	"每分钟最多请求数，0表示不限制": "max requests per minute, 0 for no limit",
+	"失败重试次数":          "retries on failure",
+	"跳过输出文件中已成功的项":    "skip items that already succeeded in the output file",
+
+	// cat.go
+	"等同于 -vET":             "equivalent to -vET",
+	"给非空行编号":               "number nonempty output lines",
+	"在行尾显示$":               "display $ at end of each line",
+	"给所有行编号":               "number all output lines",
+	"合并连续的空行":              "suppress repeated empty output lines",
+	"把TAB显示为^I":            "display TAB characters as ^I",
+	"用^和M-表示不可打印字符":        "use ^ and M- notation for nonprinting characters",
+	"显示帮助":                 "display this help",
+	"显示版本":                 "output version information",
+	"cat [文件]...":          "cat [FILE]...",
+	"显示文件内容":               "Print file contents",
+	"参数解析失败: %v":           "Error parsing arguments: %v",
+	"让AI总结文件内容":            "summarize the contents with AI",
+	"让AI解释代码":              "explain the code with AI",
+	"对文件内容向AI提问":           "ask AI a question about the contents",
+	"无效的行范围: %s":           "invalid line range: %s",
+	"AI回答:":                "AI answer:",
+	"AI解释:":                "AI explanation:",
+	"第 %d/%d 段 (第 %s 行):":  "Part %d/%d (lines %s):",
+	"[选项] 文件[:起始行-结束行]...": "[OPTION]... FILE[:START-END]...",
+
+	// cd.go
+	"cd: 参数过多":            "cd: too many arguments",
+	"cd: 没有上一个目录":         "cd: no previous directory",
+	"pushd: 参数过多":         "pushd: too many arguments",
+	"pushd: 目录栈为空":        "pushd: directory stack empty",
+	"popd: 目录栈为空":         "popd: directory stack empty",
+	"[目录|-]":              "[DIR|-]",
+	"切换工作目录，cd - 回到上一个目录": "Change the working directory, cd - goes back to the previous one",
+	"显示当前工作目录":            "Print the working directory",
+	"[目录]":                "[DIR]",
+	"把当前目录压入目录栈并切换目录":     "Push the current directory onto the stack and change directory",
+	"弹出目录栈顶并切换过去":         "Pop the top of the directory stack and change to it",
+
+	// clear.go
+	"不支持的操作系统": "Unsupported operating system",
+	"清空终端屏幕":   "Clear the terminal screen",
+
+	// client.go
+	"配置中没有profile: %s":      "No such profile in the config: %s",
+	"请在config.yaml中配置API密钥": "Please set the API key in config.yaml",
+	"模型 %s 没有返回任何结果":        "Model %s returned no result",
+
+	// compare.go
+	"失败: %v":               "failed: %v",
+	"首字 %.2fs · 总耗时 %.2fs": "first token %.2fs · total %.2fs",
+	"保留哪个回复到会话中? [1-%d，回车跳过]: ":                          "Keep which reply in the session? [1-%d, Enter to skip]: ",
+	"已保留 [%s] 的回复":                                       "Kept the reply from [%s]",
+	"用法: /compare [--columns] modelA modelB ... [-- 问题]": "Usage: /compare [--columns] modelA modelB ... [-- question]",
+	"请输入要对比的问题: ":                                        "Question to compare: ",
+	"至少需要用 -m 指定两个模型或profile":                            "At least two models or profiles are required with -m",
+	"所有模型调用均失败":                                          "All model calls failed",
+	"compare -m modelA -m modelB [问题]":                   "compare -m modelA -m modelB [question]",
+	"把同一个问题发给多个模型并对比回复":                                  "Send the same question to several models and compare the replies",
+	"[--columns] modelA modelB ... [-- 问题]":              "[--columns] modelA modelB ... [-- question]",
+	"全部完成后并排显示":                                          "show the replies side by side when all are done",
+	"参与对比的模型名或profile名，可重复指定":                            "model or profile name to compare, can be repeated",
+	"把第N个回复保存为一次会话记录":                                    "save the N-th reply as a session entry",
+
+	// edit.go
+	"编辑器 %s 执行失败: %v":       "Editor %s failed: %v",
+	"内容为空，已取消":              "Empty content, cancelled",
+	"[初始内容]":                "[initial text]",
+	"在$EDITOR中撰写问题，保存退出后发送": "Write the question in $EDITOR, it is sent when you save and quit",
+
+	// export.go
+	"不支持的导出格式: %s (可选 %s)": "Unsupported export format: %s (%s)",
+	"# AI-CLI 会话 %s\n":     "# AI-CLI session %s\n",
+	"- 开始时间: %s":           "- Started: %s",
+	"- 记录数: %d":            "- Entries: %d",
+	"- Token 合计: %s":       "- Total tokens: %s",
+	"\n## 用户 · %s\n\n%s":   "\n## User · %s\n\n%s",
+	"<details>\n<summary>思考过程</summary>\n\n%s\n\n</details>\n": "<details>\n<summary>Reasoning</summary>\n\n%s\n\n</details>\n",
+	"\n## 命令 `%s` · %s\n\n%stext\n%s\n%s":                      "\n## Command `%s` · %s\n\n%stext\n%s\n%s",
+	"\nAI总结模型: %s":                                             "\nAI summary model: %s",
+	"AI-CLI 会话":                                                "AI-CLI session",
+	"记录数":                                                      "Entries",
+	"Token 合计":                                                 "Total tokens",
+	"用户":                                                       "User",
+	"命令":                                                       "Command",
+	"AI总结模型":                                                   "AI summary model",
+	"思考过程":                                                     "Reasoning",
+
+	// extract.go
+	"服务端不支持response_format，改用提示词约束: %v":   "The server does not support response_format, falling back to the prompt: %v",
+	"第 %d 次输出未通过校验(%d 处错误)，请求模型修正...":     "Output %d failed validation (%d errors), asking the model to fix it...",
+	"输出未通过schema校验:\n  %s":                "The output failed schema validation:\n  %s",
+	"--mode 只能是 auto|schema|prompt":       "--mode must be auto|schema|prompt",
+	"extract --schema schema.json [文件|-]": "extract --schema schema.json [FILE|-]",
+	"按JSON Schema从文本中提取结构化数据":             "Extract structured data from text with a JSON Schema",
+	`按JSON Schema从文本中提取结构化数据，输出保证通过schema校验
+不指定文件或文件为"-"时读取标准输入`: "Extract structured data from text with a JSON Schema, the output always passes schema validation\nReads standard input when no file or \"-\" is given",
+	"JSON Schema文件":            "JSON Schema file",
+	"输出文件，默认写到标准输出":            "output file, standard output by default",
+	"约束方式: auto|schema|prompt": "constraint mode: auto|schema|prompt",
+	"校验失败后的修正次数":               "fix attempts after failed validation",
+
+	// history.go
+	"忽略无效的history.ignorePatterns %q: %v": "Ignoring invalid history.ignorePatterns %q: %v",
+	"%s: 历史记录中没有这一条":                     "%s: event not found",
+	"清空历史失败: %v":                         "Failed to clear the history: %v",
+	"历史记录已清空":                            "History cleared",
+	"用法: /history [N] | /history clear":  "Usage: /history [N] | /history clear",
+	"显示最近的输入历史，!N 重新执行第N条":               "Show recent input history, !N runs entry N again",
+
+	// last.go
+	"还没有命令输出":                  "No command output yet",
+	"只保存了最近 %d 条命令输出":          "Only the last %d command outputs are kept",
+	"命令 `%s` 的输出:\n%s\n%s\n%s": "Output of `%s`:\n%s\n%s\n%s",
+	"%3d  %s  (%d 行)":          "%3d  %s  (%d lines)",
+	"用法: /last [N]":            "Usage: /last [N]",
+	"用法: /ask 问题":              "Usage: /ask question",
+	"列出最近的命令输出，/last N 显示倒数第N条，问题中可用 $_、$_N 引用": "List recent command outputs, /last N prints the N-th latest, refer to them as $_ and $_N in questions",
+	"问题": "question",
+	"把最近一条命令的输出作为上下文提问": "Ask with the latest command output as context",
+
+	// ls.go
+	"显示详细信息":                                  "use a long listing format",
+	"以K、M、G显示文件大小":                            "print sizes like 1K 234M 2G",
+	"让AI总结目录内容":                               "let the AI summarize the listing",
+	"ls [路径]...":                              "ls [PATH]...",
+	"列出目录内容，ll 等同于 ls -l":                     "List directory contents, ll is ls -l",
+	"[-aAdhlrRSt1s] [路径...]":                  "[-aAdhlrRSt1s] [PATH...]",
+	"显示以.开头的文件，包括.和..":                        "do not ignore entries starting with .",
+	"显示以.开头的文件，不包括.和..":                       "do not list implied . and ..",
+	"列出目录本身而不是目录内容":                           "list directories themselves, not their contents",
+	"倒序排列":                                    "reverse order while sorting",
+	"递归列出子目录":                                 "list subdirectories recursively",
+	"按文件大小排序，大的在前":                            "sort by file size, largest first",
+	"按修改时间排序，新的在前":                            "sort by modification time, newest first",
+	"每行显示一个文件":                                "list one file per line",
+	"文件名颜色: always|auto|never":                "colorize file names: always|auto|never",
+	"--color 的值无效: %s (可选 always|auto|never)": "invalid --color value: %s (always|auto|never)",
+	"无法获取文件信息: %v":                            "Cannot stat file: %v",
+	"模板渲染失败: %v":                              "Template rendering failed: %v",
+	"AI总结:":                                   "AI summary:",
+	"未知选项: -%c":                               "unknown option: -%c",
+	"未知选项: %s":                                "unknown option: %s",
+
+	// options.go
+	"无效的超时时间: %s": "invalid timeout: %s",
+
+	// prompt.go
+	"ui.prompt 模板错误，使用默认提示符: %v": "ui.prompt template error, using the default prompt: %v",
+
+	// root.go
+	"AI回复:":           "AI reply:",
+	"AI请求失败: %v":      "AI request failed: %v",
+	"流式接收错误: %v":      "Stream error: %v",
+	"API调用失败: %v":     "API call failed: %v",
+	"\r%s思考过程:\n%s%s": "\r%sReasoning:\n%s%s",
+	"\rAI回复: %s":      "\rAI reply: %s",
+	"续写失败: %v":        "Continuation failed: %v",
+	"(回复因达到最大长度被截断，已达到自动续写次数上限)":                                "(The reply was cut off at the length limit, the auto-continue limit is reached)",
+	"(回复因达到最大长度被截断，可设置 ai.autoContinue 或 --auto-continue 自动续写)": "(The reply was cut off at the length limit, set ai.autoContinue or --auto-continue to continue automatically)",
+	"(回复被内容过滤器截断)":          "(The reply was cut off by the content filter)",
+	"思考过程:":                 "Reasoning:",
+	"(思考中...)":              "(thinking...)",
+	"用法: /reasoning on|off": "Usage: /reasoning on|off",
+	"思考过程显示: 开启":            "Show reasoning: on",
+	"思考过程显示: 关闭":            "Show reasoning: off",
+	"ai-cli [问题]":           "ai-cli [question]",
+	"AI命令行工具":               "AI command line tool",
+	`AI命令行工具，提供LLM交互功能
+不带参数运行时进入交互模式`: "AI command line tool for working with LLMs\nStarts the interactive mode when run without arguments",
+	"开启或关闭推理模型思考过程的显示":    "Turn showing the reasoning of reasoning models on or off",
+	"使用配置中profiles下的一组配置": "use one of the profiles from the config",
+	"显示推理模型的思考过程":         "show the reasoning of reasoning models",
+	"回复因长度被截断时自动续写的最多次数":  "max automatic continuations when a reply is cut off by length",
+
+	// i18n.go
+	"界面语言: zh-CN|en": "UI language: zh-CN|en",
+
+	// session.go
+	"没有找到任何会话记录": "No sessions found",
+
+	// curl.go
+	"以POST发送数据":             "HTTP POST data",
+	"HTTP错误时不输出内容":          "fail silently on HTTP errors",
+	"输出响应头":                 "include the response headers in the output",
+	"写入文件":                  "write to file",
+	"以远程文件名保存":              "save with the remote file name",
+	"静默模式":                  "silent mode",
+	"上传文件":                  "upload file",
+	"基本认证":                  "server user and password",
+	"设置User-Agent":          "send User-Agent to the server",
+	"输出详细信息":                "verbose output",
+	"让AI总结响应内容":             "let the AI summarize the response",
+	"发送HTTP请求，--ai 让AI总结响应": "Send an HTTP request, --ai lets the AI summarize the response",
+	"[选项] URL":              "[OPTION]... URL",
+	"打开文件失败: %v":            "Error opening file: %v",
+	"创建请求失败: %v":            "Error creating request: %v",
+	"请求失败: %v":              "Request failed: %v",
+	"请求失败，状态码: %d":          "Request failed with status: %d",
+	"读取响应失败: %v":            "Error reading response: %v",
+	"写入文件失败: %v":            "Error writing to file: %v",
+	"请求已取消":                 "Request cancelled",
+	"缺少URL":                 "URL is required",
+
+	// jsonschema.go
+	"schema不是合法的JSON对象: %v":  "the schema is not a valid JSON object: %v",
+	"不是合法的JSON: %v":          "not valid JSON: %v",
+	"无法解析 $ref %s":           "cannot resolve $ref %s",
+	"$ref %s 循环引用":           "$ref %s is circular",
+	"类型应为 %s，实际为 %s":         "expected type %s, got %s",
+	"类型应为 %s 之一，实际为 %s":      "expected one of the types %s, got %s",
+	"应为以下值之一 %s":             "must be one of %s",
+	"应等于 %s":                 "must equal %s",
+	"不能小于 %v":                "must be >= %v",
+	"不能大于 %v":                "must be <= %v",
+	"必须大于 %v":                "must be > %v",
+	"必须小于 %v":                "must be < %v",
+	"长度不能小于 %v":              "length must be >= %v",
+	"长度不能大于 %v":              "length must be <= %v",
+	"不匹配 pattern %s":         "does not match pattern %s",
+	"不符合 format %s":          "does not match format %s",
+	"元素个数不能少于 %v":            "must have at least %v items",
+	"元素个数不能多于 %v":            "must have at most %v items",
+	"第 %d 和第 %d 个元素重复":       "items %d and %d are equal",
+	"缺少必填字段 %s":              "missing required property %s",
+	"不允许的字段 %s":              "property %s is not allowed",
+	"不满足 anyOf 中的任何一个schema": "does not match any schema in anyOf",
+	"应恰好满足 oneOf 中的一个schema，实际满足 %d 个": "must match exactly one schema in oneOf, matched %d",
+	"不应满足 not 中的schema":                "must not match the schema in not",
+
+	// repl.go
+	"感谢使用 AI-CLI, 欢迎再次使用!":                "Thanks for using AI-CLI, see you next time!",
+	"ai-cli> 你好，请问有什么帮助么？(输入exit或quit退出)": "ai-cli> Hi, how can I help? (type exit or quit to leave)",
+	"(按下Ctrl+C不会退出程序，输入exit或quit退出)":      "(Ctrl+C does not exit, type exit or quit to leave)",
+	"未知命令: %s，输入 /help 查看可用命令":            "Unknown command: %s, type /help for the available commands",
+	"%s: 管道中只能使用内置命令和ai":                  "%s: only builtins and ai can be used in a pipeline",
+	"无法写入 %s: %v": "Cannot write %s: %v",
+	"未知命令: %s":    "Unknown command: %s",
+	"用法: %s %s":   "Usage: %s %s",
+	"别名: %s":      "Aliases: %s",
+	"选项:":         "Options:",
+	"内置命令 (不带\"/\"的命令也可以加\"/\"前缀调用，例如 /ls):": "Builtin commands (commands without \"/\" can also be called with a \"/\" prefix, e.g. /ls):",
+	"!命令 [--ai]": "!command [--ai]",
+	"通过shell执行命令，--ai 让AI解释输出": "Run a command in the shell, --ai lets the AI explain the output",
+	"!!ai [问题]": "!!ai [question]",
+	"让AI解释上一个shell命令的输出":              "Let the AI explain the output of the last shell command",
+	"其他输入会作为问题发送给AI，/help 命令 查看命令的选项": "Any other input is sent to the AI as a question, /help COMMAND shows its options",
+	"[命令]":      "[command]",
+	"显示内置命令的帮助": "Show help for the builtin commands",
+	"退出交互模式":    "Leave the interactive mode",
+
+	// sessions.go
+	"管理会话记录":                           "Manage saved sessions",
+	"列出已保存的会话":                         "List saved sessions",
+	"export [会话ID|last]":               "export [SESSION_ID|last]",
+	"导出会话为Markdown、HTML或JSONL":         "Export a session as Markdown, HTML or JSONL",
+	"md|html|jsonl [路径]":               "md|html|jsonl [path]",
+	"导出当前会话":                           "Export the current session",
+	"导出格式: md|html|jsonl":              "export format: md|html|jsonl",
+	"%s  (读取失败: %v)":                   "%s  (read failed: %v)",
+	"%s  %3d 条记录  %s":                  "%s  %3d entries  %s",
+	"会话已导出到 %s":                        "Session exported to %s",
+	"用法: /export md|html|jsonl [path]": "Usage: /export md|html|jsonl [path]",
+	"导出失败: %v":                         "Export failed: %v",
+
+	// shell.go
+	"[退出码: %d]": "[exit code: %d]",
+	"还没有执行过shell命令，先用 !命令 执行":    "No shell command yet, run one with !command first",
+	"用法: !命令 [--ai] | !!ai [问题]": "Usage: !command [--ai] | !!ai [question]",
+
+	// shellwords.go
+	"未闭合的单引号":           "unterminated single quote",
+	"未闭合的双引号":           "unterminated double quote",
+	"语法错误: 重定向只能放在命令最后": "syntax error: redirection must come last",
+	"语法错误: 重定向需要一个文件名":  "syntax error: redirection needs a file name",
+	"语法错误: 管道中有空命令":     "syntax error: empty command in the pipeline",
+
+	// term_other.go
+	"当前平台不支持终端原始模式": "Raw terminal mode is not supported on this platform",
+
+	// template.go
+	"模板 %s: 变量声明缺少结束的 ---": "template %s: the variable declarations are missing the closing ---",
+	"模板 %s: %v":               "template %s: %v",
+	"模板不存在: %s":               "No such template: %s",
+	"模板 %s 缺少变量: %s":          "template %s is missing the variable: %s",
+	"模板 %s 没有声明变量: %s":        "template %s does not declare the variable: %s",
+	"变量格式应为 k=v: %s":          "variables must be k=v: %s",
+	"%-20s (加载失败: %v)":        "%-20s (failed to load: %v)",
+	"ls -s 的目录内容总结":           "Summary of the listing for ls -s",
+	"curl --ai 的响应内容总结":       "Summary of the response for curl --ai",
+	"wget --ai 的下载内容总结":       "Summary of the download for wget --ai",
+	"!命令 --ai 和 !!ai 的命令输出解释": "Explanation of the output for !command --ai and !!ai",
+	"tree --ai 的项目概览":         "Project overview for tree --ai",
+	"run [模板名] [文件|-]":        "run [TEMPLATE] [FILE|-]",
+	"使用提示词模板提问":               "Ask with a prompt template",
+	`使用 ~/.ai-cli/templates/*.tmpl 中的提示词模板提问
+不带参数时列出所有模板。文件或标准输入的内容会作为变量 input 传入模板`: "Ask with a prompt template from ~/.ai-cli/templates/*.tmpl\nLists all templates when run without arguments. The file or standard input is passed to the template as the variable input",
+	"[模板名] [k=v ...]": "[TEMPLATE] [k=v ...]",
+	"使用提示词模板提问，不带参数时列出所有模板":          "Ask with a prompt template, lists all templates without arguments",
+	"模板变量 k=v，值为@file时读取文件，@-读取标准输入": "template variable k=v, @file reads a file and @- standard input",
+
+	// wget.go
+	"写入指定文件，- 表示输出到标准输出": "write to FILE, - for standard output",
+	"断点续传":                "resume a partial download",
+	"超时时间(秒)":             "timeout in seconds",
+	"让AI总结下载内容":           "let the AI summarize the download",
+	"下载文件，--ai 让AI总结下载内容": "Download files, --ai lets the AI summarize the download",
+	"[选项] URL...":         "[OPTION]... URL...",
+	"下载失败: %v":            "Download failed: %v",
+	"HTTP错误: %s":          "HTTP error: %s",
+	"创建文件失败: %v":          "Error creating file: %v",
+	"读取文件内容失败: %v":        "Error reading the file: %v",
+	"无效的超时时间: %v":         "invalid timeout: %v",
+	"没有指定URL":             "no URLs specified",
+
+	// tree.go
+	"显示以.开头的文件":                   "show entries starting with .",
+	"只显示目录":                       "list directories only",
+	"最多显示N层，0表示不限制":               "descend at most N levels, 0 for no limit",
+	"不按.gitignore过滤":              "do not filter by .gitignore",
+	"让AI根据目录结构和关键文件介绍项目":          "let the AI introduce the project from its layout and key files",
+	"无效的层数: %s":                   "invalid level: %s",
+	"%s 需要一个参数":                   "%s requires an argument",
+	"%s: 不是目录":                    "%s: not a directory",
+	"\n%d 个目录":                    "\n%d directories",
+	"\n%d 个目录，%d 个文件，共 %s":        "\n%d directories, %d files, %s total",
+	"tree [目录]...":                "tree [DIR]...",
+	"以树形显示目录结构，--ai 让AI介绍项目":      "Show a directory tree, --ai lets the AI introduce the project",
+	"[-ad] [-L N] [--ai] [目录...]": "[-ad] [-L N] [--ai] [DIR...]",
+
+	// grep.go
+	"递归搜索目录，没有指定路径时搜索当前目录": "search directories recursively, the current directory if no path is given",
+	"忽略大小写":     "ignore case distinctions",
+	"显示行号":      "print line numbers",
+	"显示匹配行前后N行": "print N lines of context around matches",
+	"把模式当作普通字符串而不是正则":                    "treat the pattern as a fixed string, not a regex",
+	"显示不匹配的行":                            "select non-matching lines",
+	"只显示有匹配的文件名":                         "print only names of files with matches",
+	"只搜索文件名匹配GLOB的文件，可以重复":               "search only files whose name matches GLOB, repeatable",
+	"跳过名称匹配GLOB的文件和目录，可以重复":              "skip files and directories whose name matches GLOB, repeatable",
+	"高亮匹配内容: always|auto|never":          "highlight matches: always|auto|never",
+	"让AI总结或归类匹配结果":                       "let the AI summarize or group the matches",
+	"对匹配结果向AI提问，隐含--ai":                  "ask the AI about the matches, implies --ai",
+	"无效的行数: %s":                          "invalid line count: %s",
+	"缺少搜索模式":                             "missing search pattern",
+	"无效的正则表达式: %v":                       "invalid regular expression: %v",
+	"grep: %s: 是目录":                      "grep: %s: Is a directory",
+	"grep: %s: 二进制文件，已跳过":                "grep: %s: binary file skipped",
+	"grep 模式 [文件]...":                    "grep PATTERN [FILE]...",
+	"在文件中搜索，--ai 让AI总结匹配结果":              "Search files, --ai lets the AI summarize the matches",
+	"[-rinvlF] [-C N] [--ai] 模式 [文件...]": "[-rinvlF] [-C N] [--ai] PATTERN [FILE...]",
+
+	// find.go
+	"文件名匹配GLOB":       "file name matches GLOB",
+	"文件名匹配GLOB，忽略大小写": "file name matches GLOB, ignoring case",
+	"文件类型: f普通文件，d目录，l符号链接，可以用逗号分隔多个": "file type: f regular file, d directory, l symlink, comma separated",
+	"文件大小，+表示大于，-表示小于，默认单位为512字节的块":   "file size, + for more, - for less, 512-byte blocks by default",
+	"修改时间在N天前，+表示超过N天，-表示N天以内":        "modified N days ago, + for more than N, - for less than N",
+	"最多进入N层目录":                  "descend at most N levels",
+	"让AI总结或归类找到的文件":             "let the AI summarize or group the files found",
+	"对找到的文件向AI提问，隐含--ai":        "ask the AI about the files found, implies --ai",
+	"未知的条件: %s":                 "unknown predicate: %s",
+	"无效的数值: %s":                 "invalid number: %s",
+	"-type 的值无效: %s (可选 f|d|l)": "invalid -type value: %s (f|d|l)",
+	"无效的通配符: %s":                "invalid glob: %s",
+	"-size 的值无效: %s":            "invalid -size value: %s",
+	"-mtime 的值无效: %s":           "invalid -mtime value: %s",
+	"find [路径...] [条件...]":      "find [PATH...] [PREDICATE...]",
+	"按名称、类型、大小和修改时间查找文件，--ai 让AI总结结果": "Find files by name, type, size and modification time, --ai lets the AI summarize the results",
+	`按名称、类型、大小和修改时间查找文件，--ai 让AI总结结果
+条件: -name GLOB, -iname GLOB, -type f|d|l, -size [+-]N[ckMG], -mtime [+-]N, -maxdepth N, --ai, --ask QUESTION`: "Find files by name, type, size and modification time, --ai lets the AI summarize the results\nPredicates: -name GLOB, -iname GLOB, -type f|d|l, -size [+-]N[ckMG], -mtime [+-]N, -maxdepth N, --ai, --ask QUESTION",
+	"[路径...] [-name GLOB] [-type f|d|l] [-size N] [-mtime N] [--ai]": "[PATH...] [-name GLOB] [-type f|d|l] [-size N] [-mtime N] [--ai]",
+
+	// du.go
+	"显示最大的N个目录和扩展名": "show the N largest directories and extensions",
+	"只列出N层以内的目录，-1表示不限制，0表示只统计起始目录(与GNU du相同)，更深的文件仍计入统计": "list directories at most N levels deep, -1 for no limit, 0 for the starting directory only (as in GNU du); deeper files still count",
+	"让AI指出哪些可以安全删除，不会自动删除任何文件":                            "let the AI point out what is safe to delete, nothing is deleted automatically",
+	"%s 的值无效: %s":     "invalid value for %s: %s",
+	"最大的目录:":          "Largest directories:",
+	"最大的扩展名:":         "Largest extensions:",
+	"(无扩展名)":          "(none)",
+	"%10s  %s %d 个文件": "%10s  %s %d files",
+	"总计: %s":          "Total: %s",
+	"AI建议:":           "AI suggestions:",
+	"du 不会删除任何文件，确认无误后可以用 !命令 自行删除": "du never deletes anything, once you are sure, delete with a !command yourself",
+	"du [路径]...": "du [PATH]...",
+	"统计磁盘占用，--ai 让AI给出清理建议":        "Show disk usage, --ai asks the AI for cleanup suggestions",
+	"[-n N] [-d N] [--ai] [路径...]": "[-n N] [-d N] [--ai] [PATH...]",
+}
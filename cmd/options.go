@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// errReported 表示错误信息已经输出过，命令行模式下只需要以非0状态退出
+var errReported = errors.New("")
+
+// optionFlags 从选项结构体的flag、arg、help标签生成选项说明，
+// 例如 `flag:"-d,--data" arg:"DATA" help:"以POST发送数据"`
+func optionFlags(options any) []CommandFlag {
+	var flags []CommandFlag
+	t := reflect.TypeOf(options)
+	for i := 0; i < t.NumField(); i++ {
+		if f, ok := fieldFlag(t.Field(i)); ok {
+			flags = append(flags, f)
+		}
+	}
+	return flags
+}
+
+func fieldFlag(field reflect.StructField) (CommandFlag, bool) {
+	names, ok := field.Tag.Lookup("flag")
+	if !ok {
+		return CommandFlag{}, false
+	}
+	f := CommandFlag{Arg: field.Tag.Get("arg"), Help: field.Tag.Get("help")}
+	for _, name := range strings.Split(names, ",") {
+		if strings.HasPrefix(name, "--") {
+			f.Long = name
+		} else {
+			f.Short = name
+		}
+	}
+	return f, true
+}
+
+// bindOptions 按flag标签把选项结构体的字段注册为cobra命令的选项，字段的当前值作为默认值。
+// --help 由cobra处理，不重复注册
+func bindOptions(cmd *cobra.Command, options any) {
+	v := reflect.ValueOf(options).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f, ok := fieldFlag(v.Type().Field(i))
+		if !ok || f.Long == "--help" {
+			continue
+		}
+		long := strings.TrimPrefix(f.Long, "--")
+		short := strings.TrimPrefix(f.Short, "-")
+		switch p := v.Field(i).Addr().Interface().(type) {
+		case *bool:
+			cmd.Flags().BoolVarP(p, long, short, *p, f.Help)
+		case *string:
+			cmd.Flags().StringVarP(p, long, short, *p, f.Help)
+		case *int:
+			cmd.Flags().IntVarP(p, long, short, *p, f.Help)
+		case *[]string:
+			cmd.Flags().StringArrayVarP(p, long, short, *p, f.Help)
+		case *time.Duration:
+			cmd.Flags().VarP((*secondsValue)(p), long, short, f.Help)
+		default:
+			panic(fmt.Sprintf("不支持的选项类型: %T", p))
+		}
+	}
+}
+
+// secondsValue 以秒为单位的时长选项，与REPL中 -T 30 的写法一致
+type secondsValue time.Duration
+
+func (s *secondsValue) String() string {
+	return strconv.FormatFloat(time.Duration(*s).Seconds(), 'f', -1, 64)
+}
+
+func (s *secondsValue) Set(value string) error {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf(T("无效的超时时间: %s"), value)
+	}
+	*s = secondsValue(time.Duration(seconds * float64(time.Second)))
+	return nil
+}
+
+func (s *secondsValue) Type() string {
+	return "seconds"
+}
+
+// cliQuery 命令行模式下内置命令使用的processQuery，需要时才读取AI配置。
+// 一次性的命令不需要对话历史，不创建会话，避免留下只有AI总结的会话文件
+func cliQuery(w io.Writer) func(string, bool) {
+	return func(prompt string, isBuiltin bool) {
+		newQueryProcessor(nil)(w)(prompt, isBuiltin)
+	}
+}
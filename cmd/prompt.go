@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/viper"
+)
+
+// defaultPrompt 没有配置ui.prompt时的提示符
+const defaultPrompt = "ai-cli:{{.Cwd}}> "
+
+// PromptData ui.prompt模板可用的字段
+type PromptData struct {
+	Profile   string // 当前profile，没有使用profile时为空
+	Model     string // 当前模型
+	Session   string // 会话ID
+	Cwd       string // 当前目录，主目录显示为~
+	GitBranch string // 当前目录所在git仓库的分支，不在仓库中时为空
+	Tokens    int    // 对话上下文已用的token数
+	Status    int    // 上一个 !命令 的退出码
+}
+
+// promptColors 提示符模板中可用的颜色函数
+var promptColors = map[string]string{
+	"bold":    "\033[1m",
+	"dim":     ansiDim,
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+}
+
+// promptFuncs 返回颜色函数，输出不是终端或设置了NO_COLOR时不加颜色
+func promptFuncs() template.FuncMap {
+	color := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	funcs := template.FuncMap{}
+	for name, code := range promptColors {
+		funcs[name] = func(v any) string {
+			if !color {
+				return fmt.Sprint(v)
+			}
+			return code + fmt.Sprint(v) + ansiReset
+		}
+	}
+	return funcs
+}
+
+// promptData 收集渲染提示符需要的信息
+func (r *REPL) promptData() PromptData {
+	profile := viper.GetString("ai.profile")
+	_, model, _ := profileConfig(profile)
+	data := PromptData{
+		Profile: profile,
+		Model:   model,
+		Tokens:  r.Session.ContextTokens(),
+	}
+	if r.Session != nil {
+		data.Session = r.Session.ID
+	}
+	if r.lastShell != nil {
+		data.Status = r.lastShell.ExitCode
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		data.Cwd = shortPath(cwd)
+		data.GitBranch = gitBranch(cwd)
+	}
+	return data
+}
+
+// prompt 按ui.prompt模板渲染提示符，模板有错时提示一次并使用默认提示符
+func (r *REPL) prompt() string {
+	text := viper.GetString("ui.prompt")
+	if text == "" {
+		text = defaultPrompt
+	}
+	data := r.promptData()
+	var b strings.Builder
+	tmpl, err := template.New("prompt").Funcs(promptFuncs()).Parse(text)
+	if err == nil {
+		err = tmpl.Execute(&b, data)
+	}
+	if err != nil {
+		if !r.promptWarned {
+			fmt.Printf(T("ui.prompt 模板错误，使用默认提示符: %v\n"), err)
+			r.promptWarned = true
+		}
+		return "ai-cli:" + data.Cwd + "> "
+	}
+	return b.String()
+}
+
+// gitBranch 返回dir所在git仓库的当前分支，HEAD分离时返回提交的短哈希
+func gitBranch(dir string) string {
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if !info.IsDir() {
+				// 工作树和子模块中的.git是指向真正目录的文件
+				data, err := os.ReadFile(gitDir)
+				if err != nil {
+					return ""
+				}
+				path, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+				if !ok {
+					return ""
+				}
+				if !filepath.IsAbs(path) {
+					path = filepath.Join(dir, path)
+				}
+				gitDir = path
+			}
+			head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+			if err != nil {
+				return ""
+			}
+			ref := strings.TrimSpace(string(head))
+			if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+				return branch
+			}
+			if len(ref) > 7 {
+				ref = ref[:7]
+			}
+			return ref
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
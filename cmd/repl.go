@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/viper"
+)
+
+// CommandFlag 内置命令的一个选项，用于/help和Tab补全
+type CommandFlag struct {
+	Short string // 短选项，如 "-s"
+	Long  string // 长选项，如 "--silent"
+	Arg   string // 选项参数名，为空表示开关
+	Help  string
+}
+
+// Command REPL中的内置命令
+type Command struct {
+	Name    string
+	Aliases []string
+	Usage   string // 参数说明，如 "[选项] 文件..."
+	Help    string
+	Flags   []CommandFlag
+	// Record 为true时命令输出作为命令记录保存到会话中
+	Record bool
+	// NoArgs 为true时只有单独输入命令名才算调用，避免"quit smoking tips"之类的问题被当成命令
+	NoArgs bool
+	// Operands 返回参数中的文件或目录，命令名是常见英文单词(find、cat等)时设置。
+	// 不带"/"前缀调用且参数看起来不像命令时，输入作为问题发给AI，见looksLikeQuestion
+	Operands func(args []string) ([]string, error)
+	// Complete 补全参数，args为光标前已输入的参数，word为正在输入的单词(已去掉引号和转义)
+	Complete func(r *REPL, args []string, word string) []string
+	// Run 执行命令，结果写入stdout。stdin为管道中上一个命令的输出，不在管道中时为nil
+	Run func(r *REPL, input string, stdin io.Reader, stdout io.Writer)
+}
+
+var (
+	commandList []*Command
+	commandMap  = map[string]*Command{}
+)
+
+// registerCommand 注册内置命令，名称或别名重复时panic
+func registerCommand(c *Command) {
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		if _, exists := commandMap[name]; exists {
+			panic("内置命令重复注册: " + name)
+		}
+		commandMap[name] = c
+	}
+	commandList = append(commandList, c)
+	sort.Slice(commandList, func(i, j int) bool {
+		return strings.TrimPrefix(commandList[i].Name, "/") < strings.TrimPrefix(commandList[j].Name, "/")
+	})
+}
+
+// lookupCommand 按输入的第一个单词查找内置命令，只有完全匹配名称或别名时才算命中。
+// 返回的name是去掉可选"/"前缀后的命令名，例如"/ls"对应"ls"
+func lookupCommand(input string) (c *Command, name string) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return nil, ""
+	}
+	name = fields[0]
+	c, ok := commandMap[name]
+	if !ok {
+		if trimmed, slash := strings.CutPrefix(name, "/"); slash {
+			if c, ok = commandMap[trimmed]; ok {
+				name = trimmed
+			}
+		}
+	}
+	if !ok || (c.NoArgs && len(fields) > 1) {
+		return nil, fields[0]
+	}
+	return c, name
+}
+
+// looksLikeQuestion 判断以命令名开头的输入是不是问题，例如"find the bug in my code"、
+// "du you know go?"。参数无法拆分，或者第一个参数不是选项而且参数无法解析、
+// 有多个参数但给出的路径都不存在时，认为是问题。加"/"前缀时总是作为命令执行
+func looksLikeQuestion(c *Command, input string) bool {
+	if c.Operands == nil || strings.HasPrefix(input, "/") {
+		return false
+	}
+	args, err := builtinArgs(input)
+	if err != nil {
+		return true
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return false
+	}
+	operands, err := c.Operands(args)
+	if err != nil {
+		return true
+	}
+	if len(args) < 2 || len(operands) == 0 {
+		return false
+	}
+	for _, operand := range operands {
+		if _, err := os.Lstat(operand); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// names 返回命令名及别名
+func (c *Command) names() []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+func (f CommandFlag) String() string {
+	var names []string
+	if f.Short != "" {
+		names = append(names, f.Short)
+	}
+	if f.Long != "" {
+		names = append(names, f.Long)
+	}
+	s := strings.Join(names, ", ")
+	if f.Arg != "" {
+		s += " " + f.Arg
+	}
+	return s
+}
+
+// flag 按短选项或长选项查找命令的选项
+func (c *Command) flag(name string) *CommandFlag {
+	for i, f := range c.Flags {
+		if name != "" && (f.Short == name || f.Long == name) {
+			return &c.Flags[i]
+		}
+	}
+	return nil
+}
+
+// REPL 交互模式，内置命令通过它访问会话、历史和AI请求
+type REPL struct {
+	Session *Session
+	History *History
+	Editor  *LineEditor
+	// Query 返回把AI回复写到w的processQuery
+	Query func(w io.Writer) func(string, bool)
+	done  bool
+
+	prevDir   string          // cd - 切换回的目录
+	dirStack  []string        // pushd/popd的目录栈
+	lastShell *ShellResult    // 最近一次 !命令 的结果，供 !!ai 使用
+	outputs   []commandOutput // 最近的命令输出，供 $_、/last 和 /ask 使用
+
+	promptWarned bool // 已经提示过ui.prompt模板错误
+}
+
+func NewREPL(session *Session, query func(w io.Writer) func(string, bool)) *REPL {
+	r := &REPL{
+		Session: session,
+		History: LoadHistory(),
+		Editor:  NewLineEditor(),
+		Query:   query,
+	}
+	r.Editor.ViMode = viper.GetString("ui.editMode") == "vi"
+	r.Editor.Complete = r.complete
+	return r
+}
+
+// ReadLine 读取一行，供需要追加输入的命令使用
+func (r *REPL) ReadLine(prompt string) (string, bool) {
+	line, err := r.Editor.ReadLine(prompt)
+	return line, err == nil
+}
+
+// Exit 结束交互模式
+func (r *REPL) Exit() {
+	fmt.Println(T("感谢使用 AI-CLI, 欢迎再次使用!"))
+	r.done = true
+}
+
+// Run 运行交互模式直到用户退出
+func (r *REPL) Run() {
+	fmt.Println(T("ai-cli> 你好，请问有什么帮助么？(输入exit或quit退出)"))
+
+	// 处理AI请求等过程中按下的Ctrl+C，避免程序退出
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT)
+	defer signal.Stop(sigChan)
+
+	for !r.done {
+		// 丢弃执行上一条命令期间的Ctrl+C
+		select {
+		case <-sigChan:
+		default:
+		}
+
+		r.Editor.SetHistory(r.History.Lines)
+		input, err := r.Editor.ReadMultiline(r.prompt(), "... ")
+		if err == errInterrupted {
+			fmt.Println(T("(按下Ctrl+C不会退出程序，输入exit或quit退出)"))
+			continue
+		}
+		if err != nil {
+			r.Exit()
+			return
+		}
+
+		// Skip empty input
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+
+		// !!、!N 重新执行历史记录中的输入
+		recalled, ok, err := r.History.Expand(input)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if ok {
+			fmt.Println(recalled)
+			input = recalled
+		}
+		r.History.Add(input)
+		r.Execute(strings.TrimSpace(input))
+	}
+}
+
+// Execute 执行一条输入：以!开头时交给shell执行，第一个单词是内置命令时执行命令，其余作为问题发给AI。
+// 以内置命令开头的输入可以用 | 连接多个命令，最后可以用 > 或 >> 重定向到文件
+func (r *REPL) Execute(input string) {
+	if strings.HasPrefix(input, "!") {
+		r.HandleShell(input)
+		return
+	}
+	c, name := lookupCommand(input)
+	var pipeline *Pipeline
+	if c != nil {
+		var err error
+		pipeline, err = parsePipeline(input)
+		switch {
+		case c.Operands != nil && !strings.HasPrefix(input, "/") && (err != nil || looksLikeQuestion(c, pipeline.Stages[0])):
+			// 以find、cat等常见单词开头的问题
+			c = nil
+		case err != nil:
+			fmt.Println(err)
+			return
+		}
+	}
+	if c == nil {
+		if strings.HasPrefix(name, "/") {
+			fmt.Printf(T("未知命令: %s，输入 /help 查看可用命令\n"), name)
+			return
+		}
+		question, err := r.expandLastRefs(input)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		r.Query(os.Stdout)(question, false)
+		return
+	}
+
+	commands := make([]*Command, len(pipeline.Stages))
+	for i, stage := range pipeline.Stages {
+		if commands[i], name = lookupCommand(stage); commands[i] == nil {
+			fmt.Printf(T("%s: 管道中只能使用内置命令和ai\n"), name)
+			return
+		}
+		// 统一去掉"/"前缀，命令处理函数看到的总是命令名本身
+		if !strings.HasPrefix(stage, name) {
+			pipeline.Stages[i] = strings.TrimPrefix(stage, "/")
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if pipeline.Redirect != "" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if pipeline.Append {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		f, err := os.OpenFile(pipeline.Redirect, flags, 0644)
+		if err != nil {
+			fmt.Printf(T("无法写入 %s: %v\n"), pipeline.Redirect, err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// 各个命令依次执行，上一个命令的输出作为下一个命令的输入
+	var stdin io.Reader
+	for i, stage := range pipeline.Stages {
+		if i == len(pipeline.Stages)-1 {
+			r.runCommand(commands[i], stage, stdin, out)
+			break
+		}
+		var buf bytes.Buffer
+		r.runCommand(commands[i], stage, stdin, &buf)
+		stdin = &buf
+	}
+}
+
+// runCommand 执行一个内置命令，需要记录的命令把输出保存到会话中，并留给 $_ 和 /ask 引用
+func (r *REPL) runCommand(c *Command, input string, stdin io.Reader, w io.Writer) {
+	if !c.Record {
+		c.Run(r, input, stdin, w)
+		return
+	}
+	r.Session.BeginBuiltin(input)
+	if w == os.Stdout {
+		// 直接输出到终端时连同错误信息和AI总结一起记录
+		output := captureOutput(func() { c.Run(r, input, stdin, os.Stdout) })
+		r.Session.EndBuiltin(output)
+		r.rememberOutput(input, output)
+		return
+	}
+	var record bytes.Buffer
+	c.Run(r, input, stdin, io.MultiWriter(w, &record))
+	r.Session.EndBuiltin(record.String())
+	r.rememberOutput(input, record.String())
+}
+
+// HandleHelp 处理 /help [命令]，内容由已注册的命令生成
+func HandleHelp(input string, w io.Writer) {
+	args, err := builtinArgs(input) // Skip "/help"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if len(args) > 0 {
+		c, _ := lookupCommand(args[0])
+		if c == nil {
+			fmt.Printf(T("未知命令: %s\n"), args[0])
+			return
+		}
+		fmt.Fprintf(w, T("用法: %s %s\n"), c.Name, T(c.Usage))
+		fmt.Fprintf(w, "  %s\n", T(c.Help))
+		if len(c.Aliases) > 0 {
+			fmt.Fprintf(w, T("别名: %s\n"), strings.Join(c.Aliases, ", "))
+		}
+		if len(c.Flags) > 0 {
+			fmt.Fprintln(w, T("选项:"))
+			width := 0
+			for _, f := range c.Flags {
+				width = max(width, stringWidth(f.String()))
+			}
+			for _, f := range c.Flags {
+				fmt.Fprintf(w, "  %s  %s\n", padRight(f.String(), width), T(f.Help))
+			}
+		}
+		return
+	}
+
+	fmt.Fprintln(w, T("内置命令 (不带\"/\"的命令也可以加\"/\"前缀调用，例如 /ls):"))
+	const width = 28 // 用法超过这个宽度时说明换到下一行
+	for _, c := range commandList {
+		usage := strings.TrimSpace(strings.Join(c.names(), ", ") + " " + T(c.Usage))
+		if stringWidth(usage) > width {
+			fmt.Fprintf(w, "  %s\n  %s  %s\n", usage, strings.Repeat(" ", width), T(c.Help))
+			continue
+		}
+		fmt.Fprintf(w, "  %s  %s\n", padRight(usage, width), T(c.Help))
+	}
+	fmt.Fprintf(w, "  %s  %s\n", padRight(T("!命令 [--ai]"), width), T("通过shell执行命令，--ai 让AI解释输出"))
+	fmt.Fprintf(w, "  %s  %s\n", padRight(T("!!ai [问题]"), width), T("让AI解释上一个shell命令的输出"))
+	fmt.Fprintln(w, T("其他输入会作为问题发送给AI，/help 命令 查看命令的选项"))
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:  "/help",
+		Usage: "[命令]",
+		Help:  "显示内置命令的帮助",
+		Complete: func(r *REPL, args []string, word string) []string {
+			var names []string
+			for _, c := range commandList {
+				if strings.HasPrefix(c.Name, word) {
+					names = append(names, c.Name)
+				}
+			}
+			return names
+		},
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleHelp(input, stdout) },
+	})
+	registerCommand(&Command{
+		Name:    "exit",
+		Aliases: []string{"quit"},
+		Help:    "退出交互模式",
+		NoArgs:  true,
+		Run:     func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { r.Exit() },
+	})
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestExecuteRouting(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("notes.txt", []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input    string
+		question bool
+	}{
+		{"find the bug in my code", true},
+		{"du you know go?", true},
+		{"cat is my favourite animal, tell me why", true},
+		{"tree of life explained", true},
+		{"grep the logs for errors", true},
+		{"cd into the void", true},
+		{"find . -name '*.txt'", false},
+		{"find -name '*.txt'", false},
+		{"find", false},
+		{"du -n 1", false},
+		{"du .", false},
+		{"cat notes.txt", false},
+		{"cat -n notes.txt:1", false},
+		{"cat missing.txt", false},
+		{"tree -L 1", false},
+		{"grep hello notes.txt", false},
+		{"grep -n hello", false},
+		{"cat notes.txt | grep hello", false},
+		{"/find the bug in my code", false},
+		{"cd .", false},
+	}
+	for _, tt := range tests {
+		asked := false
+		r := &REPL{Query: func(w io.Writer) func(string, bool) {
+			return func(string, bool) { asked = true }
+		}}
+		captureOutput(func() { r.Execute(tt.input) })
+		if asked != tt.question {
+			t.Errorf("Execute(%q) sent to AI = %v, want %v", tt.input, asked, tt.question)
+		}
+	}
+}
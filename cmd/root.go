@@ -1,84 +1,171 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
-	"path/filepath"
 	"strings"
-	"syscall"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-// processQuery 处理AI查询请求
-func processQuery(apiKey, model, basePath string, stream bool) func(string, bool) {
+// processQuery 处理AI查询请求，普通提问会带上会话历史并记录到session中。
+// w不是标准输出时(管道或重定向)只把回复正文写入w，提示信息写到标准错误
+func processQuery(client *aiClient, session *Session, w io.Writer) func(string, bool) {
+	model := client.Model
+	piped := w != os.Stdout
 	return func(prompt string, isSummary bool) {
-		config := openai.DefaultConfig(apiKey)
-		if basePath != "" {
-			config.BaseURL = basePath
+		var messages []openai.ChatCompletionMessage
+		if !isSummary {
+			messages = session.Messages()
 		}
-		client := openai.NewClientWithConfig(config)
-
-		if stream {
-			req := openai.ChatCompletionRequest{
-				Model: model,
-				Messages: []openai.ChatCompletionMessage{
-					{
-						Role:    openai.ChatMessageRoleUser,
-						Content: prompt,
-					},
-				},
-				Stream: true,
-			}
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: prompt,
+		})
+
+		req := openai.ChatCompletionRequest{
+			Model:    model,
+			Messages: messages,
+		}
+		showReasoning := viper.GetBool("ai.showReasoning") && !piped
 
-			stream, err := client.CreateChatCompletionStream(context.Background(), req)
+		if client.Stream && !piped {
+			fmt.Println(T("AI回复:"))
+		}
+		view := &reasoningView{show: showReasoning, out: w, terminal: !piped && isTerminal(os.Stdout)}
+		reply, err := client.chat(context.Background(), req, view.write)
+		if piped {
+			view.endReasoning()
+			if reply != nil && !client.Stream {
+				io.WriteString(w, reply.Content)
+			}
+			if reply != nil && !strings.HasSuffix(reply.Content, "\n") {
+				io.WriteString(w, "\n")
+			}
 			if err != nil {
-				fmt.Printf("API调用失败: %v\n", err)
-				os.Exit(1)
+				fmt.Fprintf(os.Stderr, T("AI请求失败: %v\n"), err)
 			}
-			defer stream.Close()
-
-			fmt.Println("AI回复:")
-			for {
-				response, err := stream.Recv()
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						break
-					}
-					fmt.Printf("\n流式接收错误: %v\n", err)
-					break
-				}
-				fmt.Print(response.Choices[0].Delta.Content)
+			if reply == nil {
+				return
 			}
+		} else if client.Stream {
+			view.endReasoning()
 			fmt.Println()
+			if err != nil {
+				fmt.Printf(T("流式接收错误: %v\n"), err)
+			}
+			if reply == nil {
+				return
+			}
 		} else {
-			resp, err := client.CreateChatCompletion(
-				context.Background(),
-				openai.ChatCompletionRequest{
-					Model: model,
-					Messages: []openai.ChatCompletionMessage{
-						{
-							Role:    openai.ChatMessageRoleUser,
-							Content: prompt,
-						},
-					},
-				},
-			)
+			if reply == nil {
+				fmt.Printf(T("API调用失败: %v\n"), err)
+				os.Exit(1)
+			}
 
+			if showReasoning && reply.Reasoning != "" {
+				fmt.Printf(T("\r%s思考过程:\n%s%s\n"), view.style(ansiDim), reply.Reasoning, view.style(ansiReset))
+			}
+			fmt.Printf(T("\rAI回复: %s\n"), reply.Content)
 			if err != nil {
-				fmt.Printf("API调用失败: %v\n", err)
-				os.Exit(1)
+				fmt.Printf(T("续写失败: %v\n"), err)
 			}
+		}
+		printFinishReason(reply.FinishReason)
+
+		if !isSummary {
+			session.AddUser(prompt)
+		}
+		session.AddAssistant(model, reply.Content, reply.Reasoning, newTokenUsage(reply.Usage))
+	}
+}
+
+// printFinishReason 提示回复没有正常结束的原因
+func printFinishReason(reason openai.FinishReason) {
+	switch reason {
+	case openai.FinishReasonLength:
+		if viper.GetInt("ai.autoContinue") > 0 {
+			fmt.Println(T("(回复因达到最大长度被截断，已达到自动续写次数上限)"))
+		} else {
+			fmt.Println(T("(回复因达到最大长度被截断，可设置 ai.autoContinue 或 --auto-continue 自动续写)"))
+		}
+	case openai.FinishReasonContentFilter:
+		fmt.Println(T("(回复被内容过滤器截断)"))
+	}
+}
+
+const (
+	ansiDim   = "\033[2m"
+	ansiReset = "\033[0m"
+)
+
+// reasoningView 流式输出时显示推理模型的思考过程：开启时暗色显示，关闭时只提示正在思考。
+// 思考过程和回复正文都写入out，只有输出到终端时才显示"思考中"提示和颜色
+type reasoningView struct {
+	out       io.Writer
+	show      bool
+	terminal  bool // out是终端，可以使用控制字符
+	reasoning bool // 正在输出思考过程
+	started   bool // 已开始输出回复
+}
 
-			fmt.Printf("\rAI回复: %s\n", resp.Choices[0].Message.Content)
+// style 输出到终端时返回code，否则返回空字符串，避免控制字符写进文件
+func (v *reasoningView) style(code string) string {
+	if !v.terminal {
+		return ""
+	}
+	return code
+}
+
+func (v *reasoningView) write(content, reasoning string) {
+	if reasoning != "" && !v.started {
+		if !v.reasoning {
+			v.reasoning = true
+			if v.show {
+				fmt.Fprint(v.out, v.style(ansiDim)+T("思考过程:\n"))
+			} else if v.terminal {
+				fmt.Fprint(v.out, ansiDim+T("(思考中...)")+ansiReset)
+			}
+		}
+		if v.show {
+			fmt.Fprint(v.out, reasoning)
+		}
+	}
+	if content != "" {
+		if !v.started {
+			v.started = true
+			v.endReasoning()
 		}
+		fmt.Fprint(v.out, content)
+	}
+}
+
+// endReasoning 结束思考过程的显示，未开启显示时擦掉"思考中"提示
+func (v *reasoningView) endReasoning() {
+	if !v.reasoning {
+		return
+	}
+	v.reasoning = false
+	if v.show {
+		fmt.Fprint(v.out, v.style(ansiReset)+"\n\n")
+	} else if v.terminal {
+		fmt.Fprint(v.out, "\r\033[K")
+	}
+}
+
+// newQueryProcessor 根据配置文件创建AI查询函数，返回的函数按输出位置生成processQuery
+func newQueryProcessor(session *Session) func(w io.Writer) func(string, bool) {
+	client, err := loadAIClient()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	return func(w io.Writer) func(string, bool) {
+		return processQuery(client, session, w)
 	}
 }
 
@@ -87,121 +174,21 @@ var rootCmd = &cobra.Command{
 	Short: "AI命令行工具",
 	Long: `AI命令行工具，提供LLM交互功能
 不带参数运行时进入交互模式`,
-	Args: cobra.MaximumNArgs(1),
+	Args:          cobra.MaximumNArgs(1),
+	SilenceErrors: true, // 错误由Execute统一输出
+	SilenceUsage:  true,
 	Run: func(cmd *cobra.Command, args []string) {
-		apiKey := viper.GetString("ai.apiKey")
-		model := viper.GetString("ai.model")
-		basePath := viper.GetString("ai.basePath")
-		stream := viper.GetBool("ai.stream")
-
-		if apiKey == "" {
-			fmt.Println("请在config.yaml中配置API密钥")
-			os.Exit(1)
-		}
-
-		queryProcessor := processQuery(apiKey, model, basePath, stream)
+		session := NewSession()
+		queryProcessor := newQueryProcessor(session)
 
 		// 交互模式
 		if len(args) == 0 {
-			fmt.Println("ai-cli> 你好，请问有什么帮助么？(输入exit或quit退出)")
-
-			// Set up interrupt handling
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, syscall.SIGINT)
-			defer signal.Stop(sigChan)
-
-			scanner := bufio.NewScanner(os.Stdin)
-			for {
-				fmt.Print("ai-cli> ")
-
-				// Read input with scanner
-				if !scanner.Scan() {
-					return
-				}
-				input := scanner.Text()
-
-				// Skip empty input
-				if input == "" {
-					continue
-				}
-
-				// Handle tab completion by checking if input ends with space+tab
-				if strings.HasSuffix(input, " \t") {
-					// Get the partial filename before tab
-					lastSpace := strings.LastIndex(input[:len(input)-2], " ")
-					prefix := ""
-					toComplete := input
-					if lastSpace >= 0 {
-						prefix = input[:lastSpace+1]
-						toComplete = input[lastSpace+1 : len(input)-2]
-					}
-
-					// Get matching files/dirs
-					matches, _ := filepath.Glob(toComplete + "*")
-					if len(matches) > 0 {
-						// If single match, complete it
-						if len(matches) == 1 {
-							completed := matches[0]
-							if isDir(completed) {
-								completed += "/"
-							}
-							input = prefix + completed
-						} else {
-							// Show all matches
-							fmt.Printf("\n%s\n", strings.Join(matches, "  "))
-						}
-					}
-
-					// Reprint prompt with completed input
-					fmt.Printf("\rai-cli> %s", input)
-					continue
-				}
-
-				// Handle Ctrl+C interrupt
-				select {
-				case <-sigChan:
-					// Clear current line and move cursor to start
-					fmt.Print("\033[2K\r")
-					// Only exit if we're not in a subcommand
-					if !strings.HasPrefix(input, " ") {
-						fmt.Println("感谢使用 AI-CLI, 欢迎再次使用!")
-						return
-					}
-					fmt.Println("(按下Ctrl+C不会退出程序，输入exit或quit退出)")
-					continue
-				default:
-					// Process the input
-					if input == "exit" || input == "quit" {
-						fmt.Println("感谢使用 AI-CLI, 欢迎再次使用!")
-						return
-					}
-					if input == "clear" {
-						HandleClear()
-						continue
-					}
-					if strings.HasPrefix(input, "cat ") {
-						HandleCat(input)
-						continue
-					}
-					if strings.HasPrefix(input, "ls") || strings.HasPrefix(input, "ll") {
-						HandleLs(input, queryProcessor)
-						continue
-					}
-					if strings.HasPrefix(input, "curl ") {
-						HandleCurl(input, queryProcessor)
-						continue
-					}
-					if strings.HasPrefix(input, "wget ") {
-						HandleWget(input, queryProcessor)
-						continue
-					}
-					queryProcessor(input, false)
-				}
-			}
+			NewREPL(session, queryProcessor).Run()
+			return
 		}
 
 		// 直接提问模式
-		queryProcessor(args[0], false)
+		queryProcessor(os.Stdout)(args[0], false)
 	},
 }
 
@@ -213,13 +200,47 @@ func isDir(path string) bool {
 	return info.IsDir()
 }
 
+// HandleReasoning 处理REPL中的 /reasoning on|off
+func HandleReasoning(input string) {
+	switch strings.TrimSpace(strings.TrimPrefix(input, "/reasoning")) {
+	case "on":
+		viper.Set("ai.showReasoning", true)
+	case "off":
+		viper.Set("ai.showReasoning", false)
+	case "":
+	default:
+		fmt.Println(T("用法: /reasoning on|off"))
+		return
+	}
+	if viper.GetBool("ai.showReasoning") {
+		fmt.Println(T("思考过程显示: 开启"))
+	} else {
+		fmt.Println(T("思考过程显示: 关闭"))
+	}
+}
+
 func init() {
-	// 不再需要prompt标志参数
+	registerCommand(&Command{
+		Name:     "/reasoning",
+		Usage:    "[on|off]",
+		Help:     "开启或关闭推理模型思考过程的显示",
+		Complete: completeWords("on", "off"),
+		Run:      func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleReasoning(input) },
+	})
+
+	rootCmd.PersistentFlags().String("profile", "", "使用配置中profiles下的一组配置")
+	viper.BindPFlag("ai.profile", rootCmd.PersistentFlags().Lookup("profile"))
+	rootCmd.PersistentFlags().Bool("show-reasoning", false, "显示推理模型的思考过程")
+	viper.BindPFlag("ai.showReasoning", rootCmd.PersistentFlags().Lookup("show-reasoning"))
+	rootCmd.PersistentFlags().Int("auto-continue", 0, "回复因长度被截断时自动续写的最多次数")
+	viper.BindPFlag("ai.autoContinue", rootCmd.PersistentFlags().Lookup("auto-continue"))
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		if err != errReported {
+			fmt.Println(err)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/viper"
+)
+
+// 会话记录中的角色
+const (
+	EntryUser      = "user"
+	EntryAssistant = "assistant"
+	EntryBuiltin   = "builtin"
+)
+
+// TokenUsage 一次AI调用的token用量
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	ReasoningTokens  int `json:"reasoning_tokens,omitempty"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func newTokenUsage(u *openai.Usage) *TokenUsage {
+	if u == nil {
+		return nil
+	}
+	usage := &TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+	if u.CompletionTokensDetails != nil {
+		usage.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return usage
+}
+
+func (u *TokenUsage) add(o *TokenUsage) {
+	if o == nil {
+		return
+	}
+	u.PromptTokens += o.PromptTokens
+	u.CompletionTokens += o.CompletionTokens
+	u.ReasoningTokens += o.ReasoningTokens
+	u.TotalTokens += o.TotalTokens
+}
+
+// Entry 会话中的一条记录：用户提问、AI回复或内置命令的输出
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Role    string    `json:"role"`
+	Model   string    `json:"model,omitempty"`
+	Command string    `json:"command,omitempty"`
+	Content string    `json:"content"`
+	// Reasoning 推理模型的思考过程，只用于展示和导出，不会发回给模型
+	Reasoning string      `json:"reasoning,omitempty"`
+	Usage     *TokenUsage `json:"usage,omitempty"`
+}
+
+// Session 一次ai-cli运行的完整对话，按JSONL逐条追加保存
+type Session struct {
+	ID      string
+	Path    string
+	Entries []Entry
+
+	pending *Entry // 正在执行的内置命令
+	created bool   // 会话文件已由本进程创建
+}
+
+// sessionDir 返回会话文件保存目录
+func sessionDir() string {
+	if dir := viper.GetString("session.dir"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".ai-cli-sessions"
+	}
+	return filepath.Join(home, ".ai-cli", "sessions")
+}
+
+// sessionEnabled 是否把会话保存到磁盘，session.enabled 为false时会话只保存在内存中
+func sessionEnabled() bool {
+	return !viper.IsSet("session.enabled") || viper.GetBool("session.enabled")
+}
+
+// NewSession 创建新会话，文件在第一条记录写入时才创建
+func NewSession() *Session {
+	s := &Session{}
+	s.newID()
+	return s
+}
+
+// newID 生成会话ID和文件路径。ID以时间开头便于按时间排序，随机后缀避免同一秒内
+// 启动的多个ai-cli写入同一个文件
+func (s *Session) newID() {
+	s.ID = fmt.Sprintf("%s-%06x", time.Now().Format("20060102-150405"), rand.IntN(1<<24))
+	s.Path = filepath.Join(sessionDir(), s.ID+".jsonl")
+	// 使用绝对路径，REPL中cd之后仍然写入同一个文件
+	if abs, err := filepath.Abs(s.Path); err == nil {
+		s.Path = abs
+	}
+}
+
+// LoadSession 按ID读取会话，ID为空或为"last"时读取最近一次会话
+func LoadSession(id string) (*Session, error) {
+	if id == "" || id == "last" {
+		ids, err := ListSessions()
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, errors.New(T("没有找到任何会话记录"))
+		}
+		id = ids[len(ids)-1]
+	}
+
+	s := &Session{ID: id, Path: filepath.Join(sessionDir(), id+".jsonl"), created: true}
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s: %v", s.Path, err)
+		}
+		s.Entries = append(s.Entries, e)
+	}
+	return s, scanner.Err()
+}
+
+// ListSessions 返回所有会话ID，按时间升序
+func ListSessions() ([]string, error) {
+	files, err := os.ReadDir(sessionDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".jsonl") {
+			ids = append(ids, strings.TrimSuffix(file.Name(), ".jsonl"))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (s *Session) append(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	s.Entries = append(s.Entries, e)
+	if !sessionEnabled() {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return
+	}
+	f, err := s.open()
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	data, _ := json.Marshal(e)
+	f.Write(append(data, '\n'))
+}
+
+// open 打开会话文件用于追加。第一次写入时用O_EXCL创建，文件已存在说明ID与其他会话冲突，
+// 换一个ID重试，保证不会与其他进程的记录交错
+func (s *Session) open() (*os.File, error) {
+	if s.created {
+		return os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	}
+	for {
+		f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if os.IsExist(err) {
+			s.newID()
+			continue
+		}
+		if err == nil {
+			s.created = true
+		}
+		return f, err
+	}
+}
+
+// AddUser 记录用户提问
+func (s *Session) AddUser(content string) {
+	if s == nil {
+		return
+	}
+	s.append(Entry{Role: EntryUser, Content: content})
+}
+
+// AddAssistant 记录AI回复，内置命令执行期间的AI总结合并到该命令的记录中
+func (s *Session) AddAssistant(model, content, reasoning string, usage *TokenUsage) {
+	if s == nil {
+		return
+	}
+	if s.pending != nil {
+		s.pending.Model = model
+		if s.pending.Usage == nil {
+			s.pending.Usage = &TokenUsage{}
+		}
+		s.pending.Usage.add(usage)
+		return
+	}
+	s.append(Entry{Role: EntryAssistant, Model: model, Content: content, Reasoning: reasoning, Usage: usage})
+}
+
+// BeginBuiltin 标记内置命令开始执行
+func (s *Session) BeginBuiltin(command string) {
+	if s == nil {
+		return
+	}
+	s.pending = &Entry{Time: time.Now(), Role: EntryBuiltin, Command: command}
+}
+
+// EndBuiltin 记录内置命令的输出
+func (s *Session) EndBuiltin(output string) {
+	if s == nil || s.pending == nil {
+		return
+	}
+	e := *s.pending
+	s.pending = nil
+	e.Content = output
+	s.append(e)
+}
+
+// ContextTokens 返回最近一次AI回复时对话上下文使用的token数
+func (s *Session) ContextTokens() int {
+	if s == nil {
+		return 0
+	}
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if e := s.Entries[i]; e.Role == EntryAssistant && e.Usage != nil {
+			return e.Usage.TotalTokens
+		}
+	}
+	return 0
+}
+
+// Messages 返回发送给模型的对话历史，内置命令的输出不包含在内
+func (s *Session) Messages() []openai.ChatCompletionMessage {
+	if s == nil {
+		return nil
+	}
+	var messages []openai.ChatCompletionMessage
+	for _, e := range s.Entries {
+		switch e.Role {
+		case EntryUser:
+			messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: e.Content})
+		case EntryAssistant:
+			messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: e.Content})
+		}
+	}
+	return messages
+}
+
+// TotalUsage 汇总整个会话的token用量
+func (s *Session) TotalUsage() TokenUsage {
+	var total TokenUsage
+	for _, e := range s.Entries {
+		total.add(e.Usage)
+	}
+	return total
+}
+
+// captureOutput 执行fn，同时把它写到标准输出的内容原样显示并收集返回
+func captureOutput(fn func()) string {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+
+	var buf strings.Builder
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.MultiWriter(stdout, &buf), r)
+		close(done)
+	}()
+
+	os.Stdout = w
+	fn()
+	os.Stdout = stdout
+	w.Close()
+	<-done
+	r.Close()
+	return buf.String()
+}
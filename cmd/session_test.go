@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSessionIDConflict(t *testing.T) {
+	viper.Set("session.dir", t.TempDir())
+	defer viper.Set("session.dir", "")
+
+	a, b := NewSession(), NewSession()
+	// 模拟两个进程在同一秒内生成了相同的ID
+	b.ID, b.Path = a.ID, a.Path
+	a.AddUser("from a")
+	b.AddUser("from b")
+	if a.ID == b.ID {
+		t.Fatalf("two sessions share the ID %s", a.ID)
+	}
+
+	for _, s := range []*Session{a, b} {
+		loaded, err := LoadSession(s.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.Entries) != 1 || loaded.Entries[0].Content != s.Entries[0].Content {
+			t.Errorf("session %s = %+v, want only %q", s.ID, loaded.Entries, s.Entries[0].Content)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "管理会话记录",
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出已保存的会话",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, err := ListSessions()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			s, err := LoadSession(id)
+			if err != nil {
+				fmt.Printf(T("%s  (读取失败: %v)\n"), id, err)
+				continue
+			}
+			fmt.Printf(T("%s  %3d 条记录  %s\n"), id, len(s.Entries), sessionTitle(s))
+		}
+		return nil
+	},
+}
+
+var sessionsExportCmd = &cobra.Command{
+	Use:   "export [会话ID|last]",
+	Short: "导出会话为Markdown、HTML或JSONL",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		}
+		s, err := LoadSession(id)
+		if err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			return ExportSession(s, format, os.Stdout)
+		}
+		path, err := ExportSessionFile(s, format, output)
+		if err != nil {
+			return err
+		}
+		fmt.Printf(T("会话已导出到 %s\n"), path)
+		return nil
+	},
+}
+
+// sessionTitle 用第一条用户提问作为会话标题
+func sessionTitle(s *Session) string {
+	for _, e := range s.Entries {
+		if e.Role == EntryUser || e.Role == EntryBuiltin {
+			title := e.Content
+			if e.Role == EntryBuiltin {
+				title = e.Command
+			}
+			title = strings.Join(strings.Fields(title), " ")
+			if r := []rune(title); len(r) > 50 {
+				title = string(r[:50]) + "..."
+			}
+			return title
+		}
+	}
+	return ""
+}
+
+// HandleExport 处理REPL中的 /export md|html|jsonl [path]
+func HandleExport(input string, session *Session) {
+	args, err := builtinArgs(input) // Skip "/export"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if len(args) == 0 {
+		fmt.Println(T("用法: /export md|html|jsonl [path]"))
+		return
+	}
+	path := ""
+	if len(args) > 1 {
+		path = args[1]
+	}
+	path, err = ExportSessionFile(session, args[0], path)
+	if err != nil {
+		fmt.Printf(T("导出失败: %v\n"), err)
+		return
+	}
+	fmt.Printf(T("会话已导出到 %s\n"), path)
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:     "/export",
+		Usage:    "md|html|jsonl [路径]",
+		Help:     "导出当前会话",
+		Complete: completeExport,
+		Run:      func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleExport(input, r.Session) },
+	})
+
+	sessionsExportCmd.Flags().StringP("format", "f", "md", "导出格式: md|html|jsonl")
+	sessionsExportCmd.Flags().StringP("output", "o", "", "输出文件，默认写到标准输出")
+	sessionsExportCmd.ValidArgsFunction = cobraWords(func() []string {
+		ids, _ := ListSessions()
+		return append(ids, "last")
+	})
+	sessionsExportCmd.RegisterFlagCompletionFunc("format", cobraWords(exportFormatNames))
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsExportCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
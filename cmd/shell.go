@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ShellResult 一次shell命令的执行结果
+type ShellResult struct {
+	Command  string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// String 返回保存到会话中的内容：标准输出、标准错误和退出码
+func (s *ShellResult) String() string {
+	var b strings.Builder
+	b.WriteString(s.Stdout)
+	if s.Stderr != "" {
+		if s.Stdout != "" && !strings.HasSuffix(s.Stdout, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("[stderr]\n" + s.Stderr)
+	}
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, T("[退出码: %d]\n"), s.ExitCode)
+	return b.String()
+}
+
+// shellCommand 用用户的shell执行命令，Windows上使用cmd
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.Command(shell, "-c", command)
+}
+
+// RunShell 执行shell命令。输出通过管道实时写到stdout和stderr，同时保存下来
+func RunShell(command string, stdout, stderr io.Writer) *ShellResult {
+	var out, errOut bytes.Buffer
+	cmd := shellCommand(command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(stdout, &out)
+	cmd.Stderr = io.MultiWriter(stderr, &errOut)
+	err := cmd.Run()
+
+	result := &ShellResult{Command: command, ExitCode: cmd.ProcessState.ExitCode()}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		// shell没能启动
+		fmt.Fprintln(stderr, err)
+		errOut.WriteString(err.Error() + "\n")
+		result.ExitCode = -1
+	}
+	result.Stdout, result.Stderr = out.String(), errOut.String()
+	return result
+}
+
+// HandleShell 处理 !命令、!命令 --ai 和 !!ai [问题]
+func (r *REPL) HandleShell(input string) {
+	command := strings.TrimSpace(strings.TrimPrefix(input, "!"))
+
+	if question, ok := strings.CutPrefix(command, "!ai"); ok && (question == "" || question[0] == ' ') {
+		if r.lastShell == nil {
+			fmt.Println(T("还没有执行过shell命令，先用 !命令 执行"))
+			return
+		}
+		r.explainShell(r.lastShell, strings.TrimSpace(question))
+		return
+	}
+
+	command, explain := strings.CutSuffix(command, " --ai")
+	command = strings.TrimSpace(command)
+	if command == "" {
+		fmt.Println(T("用法: !命令 [--ai] | !!ai [问题]"))
+		return
+	}
+
+	r.Session.BeginBuiltin("!" + command)
+	result := RunShell(command, os.Stdout, os.Stderr)
+	r.Session.EndBuiltin(result.String())
+	r.rememberOutput("!"+command, result.String())
+	r.lastShell = result
+	if result.ExitCode != 0 {
+		fmt.Printf(T("[退出码: %d]\n"), result.ExitCode)
+	}
+	if explain {
+		r.explainShell(result, "")
+	}
+}
+
+// explainShell 把命令输出和退出码发给AI解释
+func (r *REPL) explainShell(result *ShellResult, question string) {
+	vars := map[string]string{
+		"command":  result.Command,
+		"exitCode": strconv.Itoa(result.ExitCode),
+		"stdout":   result.Stdout,
+		"stderr":   result.Stderr,
+	}
+	if question != "" {
+		vars["question"] = question
+	}
+	prompt, err := renderPrompt("shell-explain", vars)
+	if err != nil {
+		fmt.Printf(T("模板渲染失败: %v\n"), err)
+		return
+	}
+	r.Query(os.Stdout)(prompt, false)
+}
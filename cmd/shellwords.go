@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// splitArgs 按POSIX shell的规则拆分命令行：支持单引号、双引号和反斜杠转义，
+// 展开开头的~、$VAR和${VAR}，未加引号的*、?、[会按通配符匹配文件，没有匹配时保留原样
+func splitArgs(input string) ([]string, error) {
+	var (
+		args    []string
+		word    strings.Builder // 展开后的单词
+		pattern strings.Builder // 用于通配符匹配的单词，加引号的通配符被转义
+		inWord  bool
+		isGlob  bool
+	)
+	// literal 写入不参与通配符匹配的文本
+	literal := func(s string) {
+		word.WriteString(s)
+		for _, r := range s {
+			if r == '*' || r == '?' || r == '[' {
+				pattern.WriteString("[" + string(r) + "]")
+			} else {
+				pattern.WriteRune(r)
+			}
+		}
+	}
+	finish := func() {
+		if !inWord {
+			return
+		}
+		var matches []string
+		if isGlob {
+			matches, _ = filepath.Glob(pattern.String())
+		}
+		if len(matches) > 0 {
+			args = append(args, matches...)
+		} else {
+			args = append(args, word.String())
+		}
+		word.Reset()
+		pattern.Reset()
+		inWord, isGlob = false, false
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			finish()
+		case r == '\\':
+			inWord = true
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' { // 反斜杠加换行表示续行
+					literal(string(runes[i]))
+				}
+			}
+		case r == '\'':
+			inWord = true
+			end := indexRune(runes, '\'', i+1)
+			if end < 0 {
+				return nil, errors.New(T("未闭合的单引号"))
+			}
+			literal(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inWord = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				switch {
+				case runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\\\"$`\n", runes[i+1]):
+					i++
+					if runes[i] != '\n' {
+						literal(string(runes[i]))
+					}
+				case runes[i] == '$':
+					value, n := expandVar(runes[i:])
+					literal(value)
+					i += n - 1
+				default:
+					literal(string(runes[i]))
+				}
+			}
+			if i >= len(runes) {
+				return nil, errors.New(T("未闭合的双引号"))
+			}
+		case r == '$':
+			inWord = true
+			value, n := expandVar(runes[i:])
+			literal(value)
+			i += n - 1
+		case r == '~' && !inWord && (i+1 == len(runes) || strings.ContainsRune("/ \t\n", runes[i+1])):
+			inWord = true
+			home, err := os.UserHomeDir()
+			if err != nil {
+				home = "~"
+			}
+			literal(home)
+		default:
+			inWord = true
+			if r == '*' || r == '?' || r == '[' {
+				isGlob = true
+			}
+			word.WriteRune(r)
+			pattern.WriteRune(r)
+		}
+	}
+	finish()
+	return args, nil
+}
+
+// expandVar 展开s开头的$NAME或${NAME}，返回变量值和消耗的字符数。不是合法变量名时原样保留$
+func expandVar(s []rune) (string, int) {
+	if len(s) > 1 && s[1] == '{' {
+		end := indexRune(s, '}', 2)
+		if end < 0 {
+			return "$", 1
+		}
+		return os.Getenv(string(s[2:end])), end + 1
+	}
+	n := 1
+	for n < len(s) && (s[n] == '_' || s[n] >= 'a' && s[n] <= 'z' || s[n] >= 'A' && s[n] <= 'Z' || n > 1 && s[n] >= '0' && s[n] <= '9') {
+		n++
+	}
+	if n == 1 {
+		return "$", 1
+	}
+	return os.Getenv(string(s[1:n])), n
+}
+
+func indexRune(s []rune, r rune, from int) int {
+	for i := from; i < len(s); i++ {
+		if s[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// builtinArgs 拆分内置命令的输入，返回命令名之后的参数
+func builtinArgs(input string) ([]string, error) {
+	args, err := splitArgs(input)
+	if err != nil || len(args) == 0 {
+		return nil, err
+	}
+	return args[1:], nil
+}
+
+// Pipeline 用 | 连接的多个命令，以及最后的输出重定向
+type Pipeline struct {
+	Stages   []string
+	Redirect string // 重定向的目标文件，为空表示输出到终端
+	Append   bool   // 为true时是 >> 追加写入
+}
+
+// parsePipeline 按未加引号的 |、> 和 >> 拆分输入。
+// 引号没有闭合时整行作为一个命令，由命令自己报告参数错误。
+// ai后面不加引号的问题是自由文本，一直到行尾都属于问题，见aiFreeText
+func parsePipeline(input string) (*Pipeline, error) {
+	p := &Pipeline{}
+	runes := []rune(input)
+	var quote rune
+	start, redirect := 0, -1
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if i == start && redirect < 0 && aiFreeText(runes[start:]) {
+			break
+		}
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			}
+		case quote == '"':
+			if r == '\\' {
+				i++
+			} else if r == '"' {
+				quote = 0
+			}
+		case r == '\\':
+			i++
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '|' || r == '>':
+			if redirect >= 0 {
+				return nil, errors.New(T("语法错误: 重定向只能放在命令最后"))
+			}
+			p.Stages = append(p.Stages, string(runes[start:i]))
+			start = i + 1
+			if r == '>' {
+				if i+1 < len(runes) && runes[i+1] == '>' {
+					p.Append = true
+					i++
+				}
+				redirect = i + 1
+			}
+		}
+	}
+	if quote != 0 {
+		return &Pipeline{Stages: []string{input}}, nil
+	}
+
+	if redirect >= 0 {
+		target, err := splitArgs(string(runes[redirect:]))
+		if err != nil {
+			return nil, err
+		}
+		if len(target) != 1 {
+			return nil, errors.New(T("语法错误: 重定向需要一个文件名"))
+		}
+		p.Redirect = target[0]
+	} else {
+		p.Stages = append(p.Stages, string(runes[start:]))
+	}
+	for i, stage := range p.Stages {
+		if p.Stages[i] = strings.TrimSpace(stage); p.Stages[i] == "" {
+			return nil, errors.New(T("语法错误: 管道中有空命令"))
+		}
+	}
+	return p, nil
+}
+
+// aiFreeText 判断stage是不是问题没有加引号的ai命令，例如 ai is a > b for all ints?。
+// 这样的问题中的 | 和 > 不是管道和重定向，避免提问时意外写入文件；
+// 问题整体加引号时，引号之后才可以接 | 或 >，如 ai "write a haiku" > out.txt
+func aiFreeText(stage []rune) bool {
+	s := strings.TrimLeft(string(stage), " \t")
+	rest, ok := strings.CutPrefix(s, "ai")
+	if !ok {
+		if rest, ok = strings.CutPrefix(s, "/ai"); !ok {
+			return false
+		}
+	}
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return false
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if rest == "" || rest[0] == '|' || rest[0] == '>' {
+		return false
+	}
+	q := rest[0]
+	if q != '"' && q != '\'' {
+		return true
+	}
+	end := -1
+	for i := 1; i < len(rest); i++ {
+		if q == '"' && rest[i] == '\\' {
+			i++
+		} else if rest[i] == q {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return true
+	}
+	after := strings.TrimLeft(rest[end+1:], " \t")
+	return after != "" && after[0] != '|' && after[0] != '>'
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"a.go", "b.go", "c.txt"} {
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("NAME", "world")
+
+	tests := []struct {
+		input string
+		args  []string
+		err   bool
+	}{
+		{"ls -l  dir", []string{"ls", "-l", "dir"}, false},
+		{`echo 'a b' "c d"`, []string{"echo", "a b", "c d"}, false},
+		{`echo a\ b`, []string{"echo", "a b"}, false},
+		{`echo "a\"b" 'a\b'`, []string{"echo", `a"b`, `a\b`}, false},
+		{"echo a\\\nb", []string{"echo", "ab"}, false},
+		{`echo $NAME ${NAME}s "$NAME" '$NAME'`, []string{"echo", "world", "worlds", "world", "$NAME"}, false},
+		{"echo $ $1x", []string{"echo", "$", "$1x"}, false},
+		{"cd ~ ~/src a~", []string{"cd", "/home/test", "/home/test/src", "a~"}, false},
+		{"ls *.go", []string{"ls", "a.go", "b.go"}, false},
+		{`ls "*.go" \*.go`, []string{"ls", "*.go", "*.go"}, false},
+		{"ls *.md", []string{"ls", "*.md"}, false},
+		{`grep "" c.txt`, []string{"grep", "", "c.txt"}, false},
+		{`echo 'unclosed`, nil, true},
+		{`echo "unclosed`, nil, true},
+	}
+	for _, tt := range tests {
+		args, err := splitArgs(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("splitArgs(%q) error = %v, want error %v", tt.input, err, tt.err)
+			continue
+		}
+		if !tt.err && !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.input, args, tt.args)
+		}
+	}
+}
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		input    string
+		stages   []string
+		redirect string
+		append   bool
+		err      bool
+	}{
+		{"ls -l", []string{"ls -l"}, "", false, false},
+		{"cat app.log | grep error | ai explain", []string{"cat app.log", "grep error", "ai explain"}, "", false, false},
+		{"ls > out.txt", []string{"ls"}, "out.txt", false, false},
+		{"ls>>out.txt", []string{"ls"}, "out.txt", true, false},
+		{`grep "a|b" x.txt > 'my file'`, []string{`grep "a|b" x.txt`}, "my file", false, false},
+		{`grep a\|b x.txt`, []string{`grep a\|b x.txt`}, "", false, false},
+		{`cat 'unclosed | grep x`, []string{`cat 'unclosed | grep x`}, "", false, false},
+		{"ai is a > b for all ints?", []string{"ai is a > b for all ints?"}, "", false, false},
+		{"ai is a > b?", []string{"ai is a > b?"}, "", false, false},
+		{"ls | ai what does 1 | 2 do", []string{"ls", "ai what does 1 | 2 do"}, "", false, false},
+		{"/ai x > y", []string{"/ai x > y"}, "", false, false},
+		{`ai "write a haiku" > out.txt`, []string{`ai "write a haiku"`}, "out.txt", false, false},
+		{`ls | ai 'summarize' | grep x`, []string{"ls", "ai 'summarize'", "grep x"}, "", false, false},
+		{`ai "a" then > b`, []string{`ai "a" then > b`}, "", false, false},
+		{"ls | ai > out.txt", []string{"ls", "ai"}, "out.txt", false, false},
+		{"aide > out.txt", []string{"aide"}, "out.txt", false, false},
+		{"ls > a | grep x", nil, "", false, true},
+		{"ls > a b", nil, "", false, true},
+		{"ls >", nil, "", false, true},
+		{"ls | | grep x", nil, "", false, true},
+	}
+	for _, tt := range tests {
+		p, err := parsePipeline(tt.input)
+		if (err != nil) != tt.err {
+			t.Errorf("parsePipeline(%q) error = %v, want error %v", tt.input, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if !reflect.DeepEqual(p.Stages, tt.stages) || p.Redirect != tt.redirect || p.Append != tt.append {
+			t.Errorf("parsePipeline(%q) = %q > %q (append %v), want %q > %q (append %v)",
+				tt.input, p.Stages, p.Redirect, p.Append, tt.stages, tt.redirect, tt.append)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateVar 模板中声明的变量
+type TemplateVar struct {
+	Name        string `yaml:"name"`
+	Default     string `yaml:"default"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// PromptTemplate 提示词模板，文件开头可以用 --- 包裹的YAML声明变量
+type PromptTemplate struct {
+	Name        string        `yaml:"-"`
+	Path        string        `yaml:"-"`
+	Description string        `yaml:"description"`
+	Vars        []TemplateVar `yaml:"vars"`
+	Body        string        `yaml:"-"`
+}
+
+// builtinTemplates 内置命令使用的总结提示词，可以在模板目录中放同名文件覆盖
+var builtinTemplates = map[string]string{
+	"ls-summary": `---
+description: ls -s 的目录内容总结
+vars:
+  - name: content
+    required: true
+    description: 文件列表
+---
+请总结以下文件列表:
+{{.content}}
+用{{language}}简洁概括目录内容`,
+	"curl-summary": `---
+description: curl --ai 的响应内容总结
+vars:
+  - name: content
+    required: true
+    description: 响应内容
+---
+请总结以下内容:
+{{.content}}
+用{{language}}简洁概括主要内容`,
+	"wget-summary": `---
+description: wget --ai 的下载内容总结
+vars:
+  - name: content
+    required: true
+    description: 下载的文件内容
+---
+请总结以下下载内容:
+{{.content}}
+用{{language}}简洁概括主要内容`,
+	"shell-explain": `---
+description: "!命令 --ai 和 !!ai 的命令输出解释"
+vars:
+  - name: command
+    required: true
+    description: 执行的命令
+  - name: exitCode
+    required: true
+    description: 退出码
+  - name: stdout
+    description: 标准输出
+  - name: stderr
+    description: 标准错误
+  - name: question
+    default: 请解释这个命令的输出；如果命令失败，分析原因并给出解决办法
+    description: 对输出的提问
+---
+我在shell中执行了命令: {{.command}}
+退出码: {{.exitCode}}
+{{- if .stdout}}
+标准输出:
+{{.stdout}}
+{{- end}}
+{{- if .stderr}}
+标准错误:
+{{.stderr}}
+{{- end}}
+{{.question}}
+请用{{language}}回答`,
+	"search-summary": `---
+description: grep --ai 和 find --ai 的搜索结果总结
+vars:
+  - name: content
+    required: true
+    description: 搜索结果
+  - name: question
+    default: 请总结这些搜索结果，按主题或用途分组，并指出值得注意的地方
+    description: 对搜索结果的提问，即 --ask 的值
+---
+以下是搜索结果:
+{{.content}}
+{{.question}}
+请用{{language}}回答`,
+	"du-cleanup": `---
+description: du --ai 的清理建议
+vars:
+  - name: report
+    required: true
+    description: 磁盘占用统计
+---
+以下是磁盘占用统计:
+{{.report}}
+请指出其中哪些目录或文件(例如构建缓存、node_modules、日志)很可能可以安全删除，说明理由、可能的风险以及删除后如何恢复，并给出对应的删除命令。不确定用途的不要建议删除。
+请用{{language}}回答`,
+	"cat-ai": `---
+description: cat --ai、--explain 和 --ask 的文件内容提问
+vars:
+  - name: content
+    required: true
+    description: 文件内容，已放在代码块中
+  - name: file
+    required: true
+    description: 文件名
+  - name: mode
+    default: summarize
+    description: summarize、explain 或 ask
+  - name: question
+    description: --ask 的问题
+  - name: language
+    description: 根据扩展名判断的语言
+  - name: lines
+    description: 内容的行范围，如 40-90
+  - name: part
+    description: 内容过长分段发送时的段号，如 2/3
+---
+以下是{{if .language}}{{.language}}{{end}}文件 {{.file}}{{if .lines}} 第 {{.lines}} 行{{end}}的内容{{if .part}}(内容较长，分段发送，这是第 {{.part}} 段，请只根据这一段回答){{end}}:
+{{.content}}
+{{if eq .mode "ask"}}{{.question}}
+{{- else if eq .mode "explain"}}请解释这段代码的作用、主要逻辑和值得注意的地方
+{{- else}}请简洁概括这部分内容
+{{- end}}
+请用{{language}}回答`,
+	"tree-overview": `---
+description: tree --ai 的项目概览
+vars:
+  - name: tree
+    required: true
+    description: 目录树
+  - name: manifests
+    description: README、go.mod、package.json等关键文件的内容
+---
+这是一个项目的目录结构:
+{{.tree}}
+{{- if .manifests}}
+关键文件:
+{{.manifests}}
+{{- end}}
+请介绍这个项目是做什么的、主要目录的作用，以及新人应该从哪里开始阅读代码。
+请用{{language}}回答`,
+}
+
+// templateDir 返回用户模板目录
+func templateDir() string {
+	if dir := viper.GetString("templates.dir"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "templates"
+	}
+	return filepath.Join(home, ".ai-cli", "templates")
+}
+
+// parseTemplate 解析模板文本中的变量声明和正文
+func parseTemplate(name, text string) (*PromptTemplate, error) {
+	tmpl := &PromptTemplate{Name: name}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if strings.HasPrefix(text, "---\n") {
+		end := strings.Index(text[4:], "\n---")
+		if end < 0 {
+			return nil, fmt.Errorf(T("模板 %s: 变量声明缺少结束的 ---"), name)
+		}
+		if err := yaml.Unmarshal([]byte(text[4:4+end]), tmpl); err != nil {
+			return nil, fmt.Errorf(T("模板 %s: %v"), name, err)
+		}
+		text = strings.TrimPrefix(text[4+end+4:], "\n")
+	}
+	tmpl.Body = text
+	return tmpl, nil
+}
+
+// LoadTemplate 按名称加载模板，用户目录中的模板优先于内置模板
+func LoadTemplate(name string) (*PromptTemplate, error) {
+	path := filepath.Join(templateDir(), name+".tmpl")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		tmpl, err := parseTemplate(name, string(data))
+		if err != nil {
+			return nil, err
+		}
+		tmpl.Path = path
+		return tmpl, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if text, ok := builtinTemplates[name]; ok {
+		return parseTemplate(name, text)
+	}
+	return nil, fmt.Errorf(T("模板不存在: %s"), name)
+}
+
+// ListTemplates 返回用户模板和内置模板的名称
+func ListTemplates() []string {
+	seen := map[string]bool{}
+	var names []string
+	matches, _ := filepath.Glob(filepath.Join(templateDir(), "*.tmpl"))
+	for _, m := range matches {
+		name := strings.TrimSuffix(filepath.Base(m), ".tmpl")
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range builtinTemplates {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render 用给定变量渲染模板，未提供的变量使用默认值
+func (t *PromptTemplate) Render(vars map[string]string) (string, error) {
+	data := map[string]string{}
+	if len(t.Vars) > 0 {
+		declared := map[string]bool{}
+		for _, v := range t.Vars {
+			declared[v.Name] = true
+			value, ok := vars[v.Name]
+			if !ok {
+				if v.Required && v.Default == "" {
+					return "", fmt.Errorf(T("模板 %s 缺少变量: %s"), t.Name, v.Name)
+				}
+				value = v.Default
+			}
+			data[v.Name] = value
+		}
+		for k := range vars {
+			if !declared[k] {
+				return "", fmt.Errorf(T("模板 %s 没有声明变量: %s"), t.Name, k)
+			}
+		}
+	} else {
+		for k, v := range vars {
+			data[k] = v
+		}
+	}
+
+	tmpl, err := template.New(t.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(t.Body)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// acceptsVar 判断模板是否接受该变量，没有声明变量的模板接受任意变量
+func (t *PromptTemplate) acceptsVar(name string) bool {
+	if len(t.Vars) == 0 {
+		return true
+	}
+	for _, v := range t.Vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// templateFuncs 模板中可用的函数，{{language}} 是当前界面语言的名称
+var templateFuncs = template.FuncMap{
+	"language": languageName,
+}
+
+// renderPrompt 加载并渲染模板
+func renderPrompt(name string, vars map[string]string) (string, error) {
+	tmpl, err := LoadTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Render(vars)
+}
+
+// parseTemplateVars 解析 k=v 形式的变量，值以@开头时读取文件内容，@-读取标准输入
+func parseTemplateVars(args []string) (map[string]string, error) {
+	vars := map[string]string{}
+	for _, arg := range args {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf(T("变量格式应为 k=v: %s"), arg)
+		}
+		if strings.HasPrefix(v, "@") {
+			content, err := readInput(v[1:])
+			if err != nil {
+				return nil, err
+			}
+			v = content
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// readInput 读取文件内容，路径为"-"时读取标准输入
+func readInput(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// stdinIsPiped 判断标准输入是否来自管道或文件
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice == 0
+}
+
+func printTemplateList() {
+	for _, name := range ListTemplates() {
+		tmpl, err := LoadTemplate(name)
+		if err != nil {
+			fmt.Printf(T("%-20s (加载失败: %v)\n"), name, err)
+			continue
+		}
+		var vars []string
+		for _, v := range tmpl.Vars {
+			vars = append(vars, v.Name)
+		}
+		fmt.Printf("%-20s %s [%s]\n", name, T(tmpl.Description), strings.Join(vars, ", "))
+	}
+}
+
+// HandleTemplate 处理REPL中的 /t NAME k=v ...
+func HandleTemplate(input string, processQuery func(string, bool)) {
+	args, err := builtinArgs(input) // Skip "/t"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if len(args) == 0 {
+		printTemplateList()
+		return
+	}
+	vars, err := parseTemplateVars(args[1:])
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	prompt, err := renderPrompt(args[0], vars)
+	if err != nil {
+		fmt.Printf(T("模板渲染失败: %v\n"), err)
+		return
+	}
+	processQuery(prompt, false)
+}
+
+var runCmd = &cobra.Command{
+	Use:   "run [模板名] [文件|-]",
+	Short: "使用提示词模板提问",
+	Long: `使用 ~/.ai-cli/templates/*.tmpl 中的提示词模板提问
+不带参数时列出所有模板。文件或标准输入的内容会作为变量 input 传入模板`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			printTemplateList()
+			return nil
+		}
+
+		varArgs, _ := cmd.Flags().GetStringArray("var")
+		vars, err := parseTemplateVars(varArgs)
+		if err != nil {
+			return err
+		}
+		tmpl, err := LoadTemplate(args[0])
+		if err != nil {
+			return err
+		}
+		if _, ok := vars["input"]; !ok && tmpl.acceptsVar("input") {
+			if len(args) > 1 {
+				vars["input"], err = readInput(args[1])
+				if err != nil {
+					return err
+				}
+			} else if stdinIsPiped() {
+				vars["input"], err = readInput("-")
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		prompt, err := tmpl.Render(vars)
+		if err != nil {
+			return err
+		}
+		newQueryProcessor(NewSession())(os.Stdout)(prompt, false)
+		return nil
+	},
+}
+
+func init() {
+	registerCommand(&Command{
+		Name:     "/t",
+		Usage:    "[模板名] [k=v ...]",
+		Help:     "使用提示词模板提问，不带参数时列出所有模板",
+		Complete: completeTemplate,
+		Run:      func(r *REPL, input string, stdin io.Reader, stdout io.Writer) { HandleTemplate(input, r.Query(stdout)) },
+	})
+
+	runCmd.Flags().StringArray("var", nil, "模板变量 k=v，值为@file时读取文件，@-读取标准输入")
+	runCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// 第二个参数是输入文件
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return filterPrefix(ListTemplates(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+	rootCmd.AddCommand(runCmd)
+}
@@ -0,0 +1,27 @@
+//go:build !unix && !windows
+
+package cmd
+
+import (
+	"errors"
+	"os"
+)
+
+// terminalWidth 不支持的平台上无法获取终端宽度
+func terminalWidth() int {
+	return 0
+}
+
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+type termState struct{}
+
+func makeRaw(f *os.File) (*termState, error) {
+	return nil, errors.New(T("当前平台不支持终端原始模式"))
+}
+
+func restoreTerminal(f *os.File, state *termState) error {
+	return nil
+}
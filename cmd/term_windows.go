@@ -0,0 +1,53 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminalWidth 返回终端宽度，标准输出不是终端时返回0
+func terminalWidth() int {
+	var info windows.ConsoleScreenBufferInfo
+	if err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info); err != nil {
+		return 0
+	}
+	return int(info.Window.Right - info.Window.Left + 1)
+}
+
+// isTerminal 判断文件是否是控制台
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
+
+type termState struct {
+	inMode, outMode uint32
+}
+
+// makeRaw 关闭控制台的行输入和回显，开启VT序列以便按键和光标控制与其他平台一致
+func makeRaw(f *os.File) (*termState, error) {
+	in := windows.Handle(f.Fd())
+	out := windows.Handle(os.Stdout.Fd())
+	state := &termState{}
+	if err := windows.GetConsoleMode(in, &state.inMode); err != nil {
+		return nil, err
+	}
+	windows.GetConsoleMode(out, &state.outMode)
+
+	raw := state.inMode &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT)
+	raw |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(in, raw); err != nil {
+		return nil, err
+	}
+	windows.SetConsoleMode(out, state.outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+	return state, nil
+}
+
+// restoreTerminal 恢复makeRaw之前的控制台模式
+func restoreTerminal(f *os.File, state *termState) error {
+	windows.SetConsoleMode(windows.Handle(os.Stdout.Fd()), state.outMode)
+	return windows.SetConsoleMode(windows.Handle(f.Fd()), state.inMode)
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// TreeOptions tree命令的选项，flag标签用于/help和Tab补全
+type TreeOptions struct {
+	All         bool   `flag:"-a,--all" help:"显示以.开头的文件"`
+	DirsOnly    bool   `flag:"-d,--dirs-only" help:"只显示目录"`
+	Level       int    `flag:"-L,--level" arg:"N" help:"最多显示N层，0表示不限制"`
+	NoIgnore    bool   `flag:"--no-ignore" help:"不按.gitignore过滤"`
+	Color       string `flag:"--color" arg:"WHEN" help:"文件名颜色: always|auto|never"`
+	AISummarize bool   `flag:"--ai" help:"让AI根据目录结构和关键文件介绍项目"`
+}
+
+func newTreeOptions() *TreeOptions {
+	return &TreeOptions{Color: "auto"}
+}
+
+// parseTreeArgs 解析tree的选项和目录，短选项可以合并写，如 -adL2
+func parseTreeArgs(args []string) (*TreeOptions, []string, error) {
+	options := newTreeOptions()
+	level := func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf(T("无效的层数: %s"), value)
+		}
+		options.Level = n
+		return nil
+	}
+
+	var dirs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return options, append(dirs, args[i+1:]...), nil
+		case arg == "--all":
+			options.All = true
+		case arg == "--dirs-only":
+			options.DirsOnly = true
+		case arg == "--no-ignore":
+			options.NoIgnore = true
+		case arg == "--ai":
+			options.AISummarize = true
+		case arg == "--color":
+			options.Color = "always"
+		case strings.HasPrefix(arg, "--color="):
+			options.Color = strings.TrimPrefix(arg, "--color=")
+		case strings.HasPrefix(arg, "--level="):
+			if err := level(strings.TrimPrefix(arg, "--level=")); err != nil {
+				return nil, nil, err
+			}
+		case arg == "--level":
+			i++
+			if i >= len(args) {
+				return nil, nil, fmt.Errorf(T("%s 需要一个参数"), arg)
+			}
+			if err := level(args[i]); err != nil {
+				return nil, nil, err
+			}
+		case strings.HasPrefix(arg, "--"):
+			return nil, nil, fmt.Errorf(T("未知选项: %s"), arg)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for j := 1; j < len(arg); j++ {
+				switch arg[j] {
+				case 'a':
+					options.All = true
+				case 'd':
+					options.DirsOnly = true
+				case 'L':
+					// 层数可以紧跟在 -L 后面，也可以是下一个参数
+					value := arg[j+1:]
+					if value == "" {
+						i++
+						if i >= len(args) {
+							return nil, nil, fmt.Errorf(T("%s 需要一个参数"), "-L")
+						}
+						value = args[i]
+					}
+					if err := level(value); err != nil {
+						return nil, nil, err
+					}
+					j = len(arg)
+				default:
+					return nil, nil, fmt.Errorf(T("未知选项: -%c"), arg[j])
+				}
+			}
+		default:
+			dirs = append(dirs, arg)
+		}
+	}
+	return options, dirs, nil
+}
+
+// HandleTree 处理tree命令，以树形显示目录结构
+func HandleTree(prompt string, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "tree"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	options, dirs, err := parseTreeArgs(args)
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
+	if err := runTree(dirs, options, w, processQuery); err != nil && err != errReported {
+		fmt.Println(err)
+	}
+}
+
+// treeWalker 遍历目录并按树形写入out
+type treeWalker struct {
+	options *TreeOptions
+	out     strings.Builder
+	color   bool
+	dirs    int
+	files   int
+	size    int64
+	failed  bool
+}
+
+// runTree 依次显示各个目录，没有指定时显示当前目录。无法读取的目录输出错误后继续，
+// 有失败时返回errReported
+func runTree(dirs []string, options *TreeOptions, w io.Writer, processQuery func(string, bool)) error {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	color, err := colorEnabled(options.Color, w)
+	if err != nil {
+		return err
+	}
+	t := &treeWalker{options: options, color: color}
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.fail(err)
+			continue
+		}
+		if !info.IsDir() {
+			t.fail(fmt.Errorf(T("%s: 不是目录"), dir))
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			t.fail(err)
+			continue
+		}
+		var rules []ignoreRule
+		if !options.NoIgnore {
+			rules = gitignoreRules(abs)
+		}
+		t.out.WriteString(t.name(dir, info.Mode()) + "\n")
+		t.walk(abs, "", 1, rules)
+	}
+	if t.options.DirsOnly {
+		fmt.Fprintf(&t.out, T("\n%d 个目录\n"), t.dirs)
+	} else {
+		fmt.Fprintf(&t.out, T("\n%d 个目录，%d 个文件，共 %s\n"), t.dirs, t.files, formatSize(t.size))
+	}
+	fmt.Fprint(w, t.out.String())
+
+	if options.AISummarize {
+		overview, err := renderPrompt("tree-overview", map[string]string{
+			"tree":      truncateTree(stripANSI(t.out.String())),
+			"manifests": projectManifests(dirs),
+		})
+		if err != nil {
+			return fmt.Errorf(T("模板渲染失败: %v"), err)
+		}
+		fmt.Fprintln(w, T("AI总结:"))
+		processQuery(overview, true)
+	}
+	if t.failed {
+		return errReported
+	}
+	return nil
+}
+
+func (t *treeWalker) fail(err error) {
+	fmt.Printf("tree: %v\n", err)
+	t.failed = true
+}
+
+// walk 输出dir下的文件，prefix是上级目录留下的竖线和空格，depth从1开始
+func (t *treeWalker) walk(dir, prefix string, depth int, rules []ignoreRule) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.fail(err)
+		return
+	}
+	// 起始目录的.gitignore已包含在gitignoreRules返回的规则中
+	if !t.options.NoIgnore && depth > 1 {
+		rules = append(rules[:len(rules):len(rules)], readGitignore(dir)...)
+	}
+
+	var entries []os.DirEntry
+	for _, file := range files {
+		name := file.Name()
+		if strings.HasPrefix(name, ".") && !t.options.All {
+			continue
+		}
+		if !t.options.NoIgnore && (name == ".git" || gitignored(rules, filepath.Join(dir, name), file.IsDir())) {
+			continue
+		}
+		if t.options.DirsOnly && !file.IsDir() {
+			continue
+		}
+		entries = append(entries, file)
+	}
+
+	for i, file := range entries {
+		branch, indent := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, indent = "└── ", "    "
+		}
+		info, err := file.Info()
+		if err != nil {
+			t.fail(err)
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		line := prefix + branch + t.name(file.Name(), info.Mode())
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if target, err := os.Readlink(path); err == nil {
+				line += " -> " + target
+			}
+			t.files++
+		case info.IsDir():
+			t.dirs++
+		default:
+			line += " (" + formatSize(info.Size()) + ")"
+			t.files++
+			t.size += info.Size()
+		}
+		t.out.WriteString(line + "\n")
+
+		// 不进入符号链接指向的目录，避免循环
+		if info.IsDir() && (t.options.Level == 0 || depth < t.options.Level) {
+			t.walk(path, prefix+indent, depth+1, rules)
+		}
+	}
+}
+
+func (t *treeWalker) name(name string, mode os.FileMode) string {
+	if !t.color {
+		return name
+	}
+	return colorName(name, mode)
+}
+
+// manifestFiles tree --ai 发给AI的关键文件，按顺序查找
+var manifestFiles = []string{
+	"README.md", "README", "readme.md", "README.rst",
+	"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "requirements.txt",
+	"pom.xml", "build.gradle", "Makefile", "Dockerfile",
+}
+
+// maxManifestSize 每个关键文件最多发送的字节数
+const maxManifestSize = 8 * 1024
+
+// maxTreeSize tree --ai 最多发送的目录树字节数，大型仓库的完整目录树会超出模型的上下文
+const maxTreeSize = 32 * 1024
+
+// truncateTree 目录树过长时在整行处截断，并保留最后的统计行
+func truncateTree(tree string) string {
+	if len(tree) <= maxTreeSize {
+		return tree
+	}
+	summary := tree[strings.LastIndex(strings.TrimRight(tree, "\n"), "\n")+1:]
+	head := tree[:maxTreeSize]
+	if i := strings.LastIndex(head, "\n"); i > 0 {
+		head = head[:i+1]
+	}
+	return head + "...\n" + summary
+}
+
+// projectManifests 读取各目录中的关键文件，每个文件放进一个代码块
+func projectManifests(dirs []string) string {
+	var b strings.Builder
+	for _, dir := range dirs {
+		for _, name := range manifestFiles {
+			path := filepath.Join(dir, name)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			content := string(data)
+			if len(content) > maxManifestSize {
+				content = content[:maxManifestSize] + "\n..."
+			}
+			fence := codeFence(content)
+			fmt.Fprintf(&b, "%s:\n%s\n%s\n%s\n\n", path, fence, strings.TrimRight(content, "\n"), fence)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func newTreeCmd() *cobra.Command {
+	options := newTreeOptions()
+	cmd := &cobra.Command{
+		Use:   "tree [目录]...",
+		Short: "以树形显示目录结构，--ai 让AI介绍项目",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTree(args, options, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	// 与GNU ls一样，--color 不带值时表示always
+	cmd.Flags().Lookup("color").NoOptDefVal = "always"
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newTreeCmd())
+	registerCommand(&Command{
+		Name:     "tree",
+		Usage:    "[-ad] [-L N] [--ai] [目录...]",
+		Help:     "以树形显示目录结构，--ai 让AI介绍项目",
+		Flags:    optionFlags(TreeOptions{}),
+		Record:   true,
+		Complete: completeDirs,
+		Operands: func(args []string) ([]string, error) {
+			_, dirs, err := parseTreeArgs(args)
+			return dirs, err
+		},
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleTree(input, stdout, r.Query(stdout))
+		},
+	})
+}
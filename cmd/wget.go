@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,23 +10,30 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/spf13/cobra"
 )
 
+// WgetOptions wget命令的选项，flag标签用于/help和Tab补全
 type WgetOptions struct {
-	OutputDocument string
-	Continue       bool
-	Quiet          bool
-	Verbose        bool
-	Timeout        time.Duration
-	UserAgent      string
-	AISummarize    bool
+	OutputDocument string        `flag:"-O,--output-document" arg:"FILE" help:"写入指定文件，- 表示输出到标准输出"`
+	Continue       bool          `flag:"-c,--continue" help:"断点续传"`
+	Quiet          bool          `flag:"-q,--quiet" help:"静默模式"`
+	Verbose        bool          `flag:"-v,--verbose" help:"输出详细信息"`
+	Timeout        time.Duration `flag:"-T,--timeout" arg:"SECONDS" help:"超时时间(秒)"`
+	UserAgent      string        `flag:"-U,--user-agent" arg:"UA" help:"设置User-Agent"`
+	AISummarize    bool          `flag:"--ai" help:"让AI总结下载内容"`
 }
 
-func parseWgetArgs(args []string) (*WgetOptions, []string, error) {
-	options := &WgetOptions{
+func newWgetOptions() *WgetOptions {
+	return &WgetOptions{
 		Timeout:   30 * time.Second,
 		UserAgent: "Wget/1.21.4",
 	}
+}
+
+func parseWgetArgs(args []string) (*WgetOptions, []string, error) {
+	options := newWgetOptions()
 	var urls []string
 
 	for i := 0; i < len(args); i++ {
@@ -44,7 +52,7 @@ func parseWgetArgs(args []string) (*WgetOptions, []string, error) {
 			i++
 			duration, err := time.ParseDuration(args[i] + "s")
 			if err != nil {
-				return nil, nil, fmt.Errorf("invalid timeout: %v", err)
+				return nil, nil, fmt.Errorf(T("无效的超时时间: %v"), err)
 			}
 			options.Timeout = duration
 		case "-U", "--user-agent":
@@ -54,53 +62,91 @@ func parseWgetArgs(args []string) (*WgetOptions, []string, error) {
 			options.AISummarize = true
 		default:
 			if strings.HasPrefix(arg, "-") {
-				return nil, nil, fmt.Errorf("unknown option: %s", arg)
+				return nil, nil, fmt.Errorf(T("未知选项: %s"), arg)
 			}
 			urls = append(urls, arg)
 		}
 	}
 
 	if len(urls) == 0 {
-		return nil, nil, fmt.Errorf("no URLs specified")
+		return nil, nil, errors.New(T("没有指定URL"))
 	}
 
 	return options, urls, nil
 }
 
-func HandleWget(prompt string, processQuery func(string, bool)) {
-	args := strings.Fields(prompt)[1:] // Skip "wget"
+func HandleWget(prompt string, w io.Writer, processQuery func(string, bool)) {
+	args, err := builtinArgs(prompt) // Skip "wget"
+	if err != nil {
+		fmt.Printf(T("参数解析失败: %v\n"), err)
+		return
+	}
 	options, urls, err := parseWgetArgs(args)
 	if err != nil {
-		fmt.Printf("Error parsing arguments: %v\n", err)
+		fmt.Printf(T("参数解析失败: %v\n"), err)
 		return
 	}
+	runWget(urls, options, w, processQuery)
+}
+
+// runWget 依次下载各个URL，出错的URL输出错误后继续，有失败时返回errReported
+func runWget(urls []string, options *WgetOptions, w io.Writer, processQuery func(string, bool)) error {
+	failed := false
+	fail := func(format string, a ...any) {
+		fmt.Printf(T(format), a...)
+		failed = true
+	}
 
 	client := &http.Client{
 		Timeout: options.Timeout,
 	}
+	// -O - 把下载内容写到输出，便于在管道中使用
+	toStdout := options.OutputDocument == "-"
+	summarize := func(content []byte) {
+		summaryPrompt, err := renderPrompt("wget-summary", map[string]string{"content": string(content)})
+		if err != nil {
+			fail("模板渲染失败: %v\n", err)
+			return
+		}
+		fmt.Fprintln(w, T("AI总结:"))
+		processQuery(summaryPrompt, true)
+	}
 
 	for _, urlStr := range urls {
 		startTime := time.Now()
-		if !options.Quiet {
-			fmt.Printf("--%s--  %s\n", startTime.Format("2006-01-02 15:04:05"), urlStr)
+		if !options.Quiet && !toStdout {
+			fmt.Fprintf(w, "--%s--  %s\n", startTime.Format("2006-01-02 15:04:05"), urlStr)
 		}
 
 		req, err := http.NewRequest("GET", urlStr, nil)
 		if err != nil {
-			fmt.Printf("创建请求失败: %v\n", err)
+			fail("创建请求失败: %v\n", err)
 			continue
 		}
 		req.Header.Set("User-Agent", options.UserAgent)
 
 		resp, err := client.Do(req)
 		if err != nil {
-			fmt.Printf("下载失败: %v\n", err)
+			fail("下载失败: %v\n", err)
 			continue
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			fmt.Printf("HTTP错误: %s\n", resp.Status)
+			fail("HTTP错误: %s\n", resp.Status)
+			continue
+		}
+
+		if toStdout {
+			content, err := io.ReadAll(resp.Body)
+			if err != nil {
+				fail("下载失败: %v\n", err)
+				continue
+			}
+			w.Write(content)
+			if options.AISummarize {
+				summarize(content)
+			}
 			continue
 		}
 
@@ -140,14 +186,14 @@ func HandleWget(prompt string, processQuery func(string, bool)) {
 			file, err = os.Create(outputPath)
 		}
 		if err != nil {
-			fmt.Printf("创建文件失败: %v\n", err)
+			fail("创建文件失败: %v\n", err)
 			continue
 		}
 		defer file.Close()
 
 		_, err = io.Copy(file, resp.Body)
 		if err != nil {
-			fmt.Printf("写入文件失败: %v\n", err)
+			fail("写入文件失败: %v\n", err)
 			continue
 		}
 
@@ -157,9 +203,9 @@ func HandleWget(prompt string, processQuery func(string, bool)) {
 			size := fileInfo.Size()
 			speed := float64(size) / duration.Seconds() / 1024
 
-			fmt.Printf("Length: %d [%s]\n", size, resp.Header.Get("Content-Type"))
-			fmt.Printf("Saving to: '%s'\n", outputPath)
-			fmt.Printf("\n%s (%0.1f KB/s) - '%s' saved [%d/%d]\n",
+			fmt.Fprintf(w, "Length: %d [%s]\n", size, resp.Header.Get("Content-Type"))
+			fmt.Fprintf(w, "Saving to: '%s'\n", outputPath)
+			fmt.Fprintf(w, "\n%s (%0.1f KB/s) - '%s' saved [%d/%d]\n",
 				time.Now().Format("2006-01-02 15:04:05"),
 				speed,
 				outputPath,
@@ -171,13 +217,44 @@ func HandleWget(prompt string, processQuery func(string, bool)) {
 			file.Seek(0, 0)
 			content, err := io.ReadAll(file)
 			if err != nil {
-				fmt.Printf("读取文件内容失败: %v\n", err)
+				fail("读取文件内容失败: %v\n", err)
 				continue
 			}
-
-			summaryPrompt := fmt.Sprintf("请总结以下下载内容:\n%s\n用中文简洁概括主要内容", string(content))
-			fmt.Println("AI总结:")
-			processQuery(summaryPrompt, true)
+			summarize(content)
 		}
 	}
+	if failed {
+		return errReported
+	}
+	return nil
+}
+
+func newWgetCmd() *cobra.Command {
+	options := newWgetOptions()
+	cmd := &cobra.Command{
+		Use:               "wget URL...",
+		Short:             "下载文件，--ai 让AI总结下载内容",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: cobraURLs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWget(args, options, os.Stdout, cliQuery(os.Stdout))
+		},
+	}
+	bindOptions(cmd, options)
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(newWgetCmd())
+	registerCommand(&Command{
+		Name:     "wget",
+		Usage:    "[选项] URL...",
+		Help:     "下载文件，--ai 让AI总结下载内容",
+		Flags:    optionFlags(WgetOptions{}),
+		Record:   true,
+		Complete: completeURL,
+		Run: func(r *REPL, input string, stdin io.Reader, stdout io.Writer) {
+			HandleWget(input, stdout, r.Query(stdout))
+		},
+	})
 }
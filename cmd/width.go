@@ -0,0 +1,77 @@
+package cmd
+
+import "unicode"
+
+// runeWidth 返回字符在终端中占用的列数，中日韩文字和全角符号占两列
+func runeWidth(r rune) int {
+	switch {
+	case r == 0 || r < 32 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115f,
+		r >= 0x2e80 && r <= 0x303e,
+		r >= 0x3041 && r <= 0x33ff,
+		r >= 0x3400 && r <= 0x4dbf,
+		r >= 0x4e00 && r <= 0x9fff,
+		r >= 0xa000 && r <= 0xa4cf,
+		r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff,
+		r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60,
+		r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f,
+		r >= 0x1f900 && r <= 0x1f9ff,
+		r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
+
+// stringWidth 返回字符串在终端中占用的列数
+func stringWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// wrapText 按显示宽度把文本折成多行
+func wrapText(s string, width int) []string {
+	var lines []string
+	for _, para := range splitLines(s) {
+		line, w := []rune{}, 0
+		for _, r := range para {
+			rw := runeWidth(r)
+			if w+rw > width && len(line) > 0 {
+				lines = append(lines, string(line))
+				line, w = line[:0], 0
+			}
+			line = append(line, r)
+			w += rw
+		}
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// padRight 用空格把字符串补齐到指定显示宽度
+func padRight(s string, width int) string {
+	for w := stringWidth(s); w < width; w++ {
+		s += " "
+	}
+	return s
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			lines = append(lines, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(lines, s[start:])
+}